@@ -10,11 +10,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"oc/internal/config"
 	"oc/internal/opencodestorage"
+	"oc/internal/state"
 	"oc/internal/tui"
 )
 
@@ -30,6 +33,12 @@ func run(args []string) int {
 	if len(args) > 0 && args[0] == "upgrade" {
 		return runUpgrade(args[1:])
 	}
+	if len(args) > 0 && args[0] == "config" {
+		return runConfig(args[1:])
+	}
+	if len(args) > 0 && args[0] == "search" {
+		return runSearch(args[1:])
+	}
 
 	fs := flag.NewFlagSet("oc", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
@@ -40,11 +49,14 @@ func run(args []string) int {
 	fs.BoolVar(showVersion, "v", false, "show version")
 	upgradeFlag := fs.Bool("upgrade", false, "upgrade oc via install script")
 	dryRun := fs.Bool("dry-run", false, "print opencode command and exit")
+	noColor := fs.Bool("no-color", false, "disable colors (also: NO_COLOR)")
 	legacyFlag := fs.Bool("legacy", false, "also read legacy JSON storage (storage/**) and merge with SQLite")
+	inlineFlag := fs.Bool("inline", false, "render a compact picker below the prompt instead of full screen")
 
 	storageRootFlag := fs.String("storage", "", "OpenCode storage root (default: ~/.local/share/opencode)")
-	configPathFlag := fs.String("config", "", "Config path (default: ~/.config/oc/oc-config.yaml)")
+	configPathFlag := fs.String("config", "", "Config path (default: $XDG_CONFIG_HOME/oc/oc-config.yaml)")
 	dbPathFlag := fs.String("db", "", "OpenCode database path (default: <storageRoot>/opencode.db)")
+	searchFlag := fs.String("search", "", "open global search with this query")
 
 	fs.Usage = func() {
 		fmt.Fprintln(fs.Output(), "oc - speed-first OpenCode launcher")
@@ -52,6 +64,8 @@ func run(args []string) int {
 		fmt.Fprintln(fs.Output(), "Usage:")
 		fmt.Fprintln(fs.Output(), "  oc            launch project picker")
 		fmt.Fprintln(fs.Output(), "  oc upgrade    upgrade oc via install script")
+		fmt.Fprintln(fs.Output(), "  oc config     show, validate or print the schema of the config")
+		fmt.Fprintln(fs.Output(), "  oc search     list, save, delete or run saved searches")
 		fmt.Fprintln(fs.Output(), "  oc --upgrade  upgrade oc via install script")
 		fmt.Fprintln(fs.Output(), "  oc --help     show this help")
 		fmt.Fprintln(fs.Output(), "  oc --version  show version")
@@ -59,7 +73,10 @@ func run(args []string) int {
 		fmt.Fprintln(fs.Output(), "  oc --config <path>   override model config path")
 		fmt.Fprintln(fs.Output(), "  oc --db <path>       override OpenCode SQLite database path")
 		fmt.Fprintln(fs.Output(), "  oc --legacy          also read legacy JSON storage (storage/**)")
+		fmt.Fprintln(fs.Output(), "  oc --search <query>  open global search with a query")
+		fmt.Fprintln(fs.Output(), "  oc --inline          compact picker below the prompt (keeps scrollback)")
 		fmt.Fprintln(fs.Output(), "  oc --dry-run         print opencode command, do not launch")
+		fmt.Fprintln(fs.Output(), "  oc --no-color        disable colors")
 		fmt.Fprintln(fs.Output())
 		fmt.Fprintln(fs.Output(), "Upgrade notes:")
 		fmt.Fprintf(fs.Output(), "  - Runs installer from: %s\n", installScriptURL)
@@ -68,13 +85,18 @@ func run(args []string) int {
 		fmt.Fprintln(fs.Output())
 		fmt.Fprintln(fs.Output(), "Data sources:")
 		fmt.Fprintln(fs.Output(), "  OpenCode storage: ~/.local/share/opencode")
-		fmt.Fprintln(fs.Output(), "  Model config:     ~/.config/oc/oc-config.yaml")
+		fmt.Fprintln(fs.Output(), "  Model config:     $XDG_CONFIG_DIRS/oc/oc-config.yaml (system, default /etc/xdg)")
+		fmt.Fprintln(fs.Output(), "                    $XDG_CONFIG_HOME/oc/oc-config.yaml (user, default ~/.config)")
+		fmt.Fprintln(fs.Output(), "                    <project>/.oc.yaml (project-local models only)")
+		fmt.Fprintln(fs.Output(), "  oc state:         $XDG_STATE_HOME/oc (pins, notes, searches, launch history; default ~/.local/state)")
 		fmt.Fprintln(fs.Output())
 		fmt.Fprintln(fs.Output(), "Environment overrides:")
 		fmt.Fprintln(fs.Output(), "  OC_STORAGE_ROOT")
 		fmt.Fprintln(fs.Output(), "  OC_CONFIG_PATH")
 		fmt.Fprintln(fs.Output(), "  OC_DB_PATH")
+		fmt.Fprintln(fs.Output(), "  OC_STATE_DIR")
 		fmt.Fprintln(fs.Output(), "  OC_DISABLE_SQLITE=1")
+		fmt.Fprintln(fs.Output(), "  NO_COLOR=1          same as --no-color")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -108,13 +130,12 @@ func run(args []string) int {
 		storageRoot = filepath.Join(home, ".local", "share", "opencode")
 	}
 
-	configPath := strings.TrimSpace(os.Getenv("OC_CONFIG_PATH"))
-	if configPath == "" {
-		configPath = strings.TrimSpace(*configPathFlag)
-	}
-	if configPath == "" {
-		configPath = filepath.Join(home, ".config", "oc", "oc-config.yaml")
+	layers, err := configLayers(*configPathFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot determine config path: %v\n", err)
+		return 1
 	}
+	configPath := layers[len(layers)-1].Path
 
 	useLegacy := *legacyFlag
 	disableSQLite := strings.TrimSpace(os.Getenv("OC_DISABLE_SQLITE")) == "1"
@@ -140,23 +161,13 @@ func run(args []string) int {
 		return 1
 	}
 
-	store, err := opencodestorage.OpenStore(opencodestorage.OpenOptions{
-		StorageRoot:   storageRoot,
-		DBPath:        dbPath,
-		UseLegacy:     useLegacy,
-		DisableSQLite: disableSQLite,
-	})
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: failed to open storage: %v\n", err)
+	modelCfg, err := config.LoadLayered(layers)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "error: invalid model config")
+		fmt.Fprintf(os.Stderr, "  detail: %v\n", err)
+		fmt.Fprintln(os.Stderr, "  run `oc config validate` to list every problem with its line and column")
 		return 1
 	}
-	defer func() {
-		// NOTE: if we exec into opencode, defers don't run; we'll also close
-		// explicitly after the TUI returns.
-		_ = store.Close()
-	}()
-
-	modelCfg, err := config.Load(configPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error: model config missing/unreadable")
 		fmt.Fprintf(os.Stderr, "  expected: %s\n", configPath)
@@ -166,6 +177,9 @@ func run(args []string) int {
 		fmt.Fprintln(os.Stderr, strings.TrimSpace(config.MinimalExampleYAML()))
 		return 1
 	}
+	if err := modelCfg.DiscoverModels(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
 	defaultModel, err := modelCfg.Default()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error: invalid model config")
@@ -173,16 +187,37 @@ func run(args []string) int {
 		return 1
 	}
 
+	store, err := opencodestorage.OpenStore(opencodestorage.OpenOptions{
+		StorageRoot:   storageRoot,
+		DBPath:        dbPath,
+		UseLegacy:     useLegacy,
+		DisableSQLite: disableSQLite,
+		// Counting messages is costly; only min_messages policies need it.
+		CountMessages: modelCfg.CountsMessages(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to open storage: %v\n", err)
+		return 1
+	}
+	defer func() {
+		// NOTE: if we exec into opencode, defers don't run; we'll also close
+		// explicitly after the TUI returns.
+		_ = store.Close()
+	}()
+
 	projects, err := store.Projects(context.Background())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to load projects: %v\n", err)
 		return 1
 	}
+	loadedAt := time.Now()
 	if len(projects) == 0 {
 		fmt.Fprintln(os.Stderr, "error: no projects found (JSON or SQLite)")
 		return 1
 	}
 
+	local := loadState()
+
 	plan, err := tui.Run(tui.Input{
 		Store:                    store,
 		Projects:                 projects,
@@ -190,6 +225,27 @@ func run(args []string) int {
 		DefaultModel:             defaultModel,
 		HideGlobalProjects:       modelCfg.UI.HideGlobalProjects,
 		GlobalSessionsMaxAgeDays: modelCfg.UI.GlobalSessionsMaxAgeDays,
+		ProjectModels:            modelCfg.ForProject,
+		ProjectDisplay:           modelCfg.ProjectDisplay,
+		SessionLimits:            modelCfg.ProjectSessionLimits,
+		RecentLimits:             modelCfg.RecentSessionLimits(),
+		RecentByProject:          modelCfg.UI.RecentByProject(),
+		Pins:                     local.pins,
+		Notes:                    local.notes,
+		Launches:                 local.launches,
+		Searches:                 local.searches,
+		SearchHistory:            modelCfg.UI.SearchHistoryLimit(),
+		SearchQuery:              *searchFlag,
+		Sort:                     modelCfg.UI.Sort,
+		SubstringFilter:          modelCfg.UI.SubstringFilter(),
+		Mouse:                    modelCfg.UI.Mouse,
+		Vim:                      modelCfg.UI.Vim,
+		Inline:                   *inlineFlag || modelCfg.UI.Inline,
+		InlineHeight:             modelCfg.UI.InlineLines(),
+		LoadedAt:                 loadedAt,
+		Keys:                     modelCfg.KeyBindings(),
+		Theme:                    modelCfg.UI.Theme,
+		NoColor:                  *noColor || os.Getenv("NO_COLOR") != "",
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -200,13 +256,11 @@ func run(args []string) int {
 	}
 	// Close DB handles before exec'ing into opencode.
 	_ = store.Close()
+
+	args2, env := opencodeArgs(plan)
 	if *dryRun {
 		// Print a shell-friendly line (quote values, not flags).
-		fmt.Fprintf(os.Stdout, "opencode %q --model %q", plan.ProjectDir, plan.Model.Model)
-		if plan.SessionID != "" {
-			fmt.Fprintf(os.Stdout, " --session %q", plan.SessionID)
-		}
-		fmt.Fprintln(os.Stdout)
+		fmt.Fprintln(os.Stdout, shellLine(env, args2))
 		return 0
 	}
 
@@ -216,12 +270,14 @@ func run(args []string) int {
 		return 1
 	}
 
-	args2 := []string{plan.ProjectDir, "--model", plan.Model.Model}
-	if plan.SessionID != "" {
-		args2 = append(args2, "--session", plan.SessionID)
+	if local.dir != "" {
+		launch := state.Launch{Time: time.Now(), Project: plan.ProjectDir, SessionID: plan.SessionID, Model: plan.Model.Model}
+		if err := state.AppendLaunch(local.dir, launch); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: cannot record launch: %v\n", err)
+		}
 	}
 
-	if err := execOpencode(plan.ProjectDir, args2); err != nil {
+	if err := execOpencode(plan.ProjectDir, args2, env); err != nil {
 		var ee *exec.ExitError
 		if errors.As(err, &ee) {
 			return ee.ExitCode()
@@ -233,6 +289,342 @@ func run(args []string) int {
 	return 0
 }
 
+// opencodeArgs builds the opencode argument list and extra environment for a
+// launch plan.
+func opencodeArgs(plan *tui.LaunchPlan) (args []string, env []string) {
+	args = []string{plan.ProjectDir, "--model", plan.Model.Model}
+	if plan.SessionID != "" {
+		args = append(args, "--session", plan.SessionID)
+	}
+	p := plan.Profile
+	if p == nil {
+		return args, nil
+	}
+	if a := strings.TrimSpace(p.Agent); a != "" {
+		args = append(args, "--agent", a)
+	}
+	args = append(args, p.Args...)
+
+	keys := make([]string, 0, len(p.Env))
+	for k := range p.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+p.Env[k])
+	}
+	return args, env
+}
+
+// mergeEnv returns base with the KEY=value entries of extra applied: a key
+// set in extra replaces its entry in base, so the process never sees the
+// variable twice.
+func mergeEnv(base, extra []string) []string {
+	set := make(map[string]bool, len(extra))
+	for _, kv := range extra {
+		k, _, _ := strings.Cut(kv, "=")
+		set[k] = true
+	}
+	out := make([]string, 0, len(base)+len(extra))
+	for _, kv := range base {
+		if k, _, _ := strings.Cut(kv, "="); !set[k] {
+			out = append(out, kv)
+		}
+	}
+	return append(out, extra...)
+}
+
+// shellLine renders env assignments and an opencode invocation, quoting
+// values but not flags.
+func shellLine(env []string, args []string) string {
+	var b strings.Builder
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "%s=%q ", k, v)
+	}
+	b.WriteString("opencode")
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") {
+			b.WriteString(" " + a)
+			continue
+		}
+		fmt.Fprintf(&b, " %q", a)
+	}
+	return b.String()
+}
+
+// configLayers resolves the config layers. OC_CONFIG_PATH or --config replace
+// the user layer.
+func configLayers(flagPath string) ([]config.Layer, error) {
+	p := strings.TrimSpace(os.Getenv("OC_CONFIG_PATH"))
+	if p == "" {
+		p = strings.TrimSpace(flagPath)
+	}
+	return config.LayerPaths(p)
+}
+
+func runConfig(args []string) int {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "oc config - inspect oc configuration")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintln(os.Stderr, "  oc config show [--config <path>] [--project <dir>]")
+		fmt.Fprintln(os.Stderr, "  oc config validate [--config <path>] [--project <dir>]")
+		fmt.Fprintln(os.Stderr, "  oc config schema")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Notes:")
+		fmt.Fprintln(os.Stderr, "  - show prints the effective config; each value is annotated with the file that set it")
+		fmt.Fprintln(os.Stderr, "  - validate reports every problem as file:line:column and exits 1 on errors")
+		fmt.Fprintln(os.Stderr, "  - schema prints the JSON Schema for oc-config.yaml")
+		fmt.Fprintln(os.Stderr, "  - --project layers <dir>/.oc.yaml on top (default: current directory)")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	switch args[0] {
+	case "--help", "-h", "help":
+		usage()
+		return 0
+	case "show":
+		return runConfigShow(args[1:])
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "schema":
+		_, _ = os.Stdout.Write(config.JSONSchema())
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "error: unknown config command %q\n", args[0])
+	usage()
+	return 2
+}
+
+func runConfigShow(args []string) int {
+	fs := flag.NewFlagSet("oc config show", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	configPathFlag := fs.String("config", "", "Config path (default: $XDG_CONFIG_HOME/oc/oc-config.yaml)")
+	projectFlag := fs.String("project", "", "project directory whose .oc.yaml is applied (default: current directory)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	layers, err := configLayers(*configPathFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot determine config path: %v\n", err)
+		return 1
+	}
+	project := strings.TrimSpace(*projectFlag)
+	if project == "" {
+		project, _ = os.Getwd()
+	}
+
+	fmt.Fprintln(os.Stdout, "# layers (later overrides earlier):")
+	for _, l := range layers {
+		fmt.Fprintf(os.Stdout, "#   %s (%s)\n", l.Path, layerStatus(l.Path))
+	}
+	if project != "" {
+		p := filepath.Join(project, config.ProjectConfigName)
+		fmt.Fprintf(os.Stdout, "#   %s (%s)\n", p, layerStatus(p))
+	}
+
+	cfg, err := config.LoadLayered(layers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if project != "" {
+		if cfg, err = cfg.WithProject(project); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+	out, err := cfg.AnnotatedYAML()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	_, _ = os.Stdout.Write(out)
+
+	if cfg.Discovery.OpenCode {
+		if err := cfg.DiscoverModels(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		pm, _ := cfg.ForProject(project)
+		for _, m := range pm.Models {
+			if m.Discovered {
+				fmt.Fprintf(os.Stdout, "# discovered (opencode): %s\n", m.Model)
+			}
+		}
+	}
+	return 0
+}
+
+func runConfigValidate(args []string) int {
+	fs := flag.NewFlagSet("oc config validate", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	configPathFlag := fs.String("config", "", "Config path (default: $XDG_CONFIG_HOME/oc/oc-config.yaml)")
+	projectFlag := fs.String("project", "", "project directory whose .oc.yaml is checked too (default: current directory)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	layers, err := configLayers(*configPathFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot determine config path: %v\n", err)
+		return 1
+	}
+	project := strings.TrimSpace(*projectFlag)
+	if project == "" {
+		project, _ = os.Getwd()
+	}
+
+	diags := config.Validate(layers, project)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stdout, d.String())
+	}
+	if config.HasErrors(diags) {
+		return 1
+	}
+	if len(diags) == 0 {
+		fmt.Fprintln(os.Stdout, "config ok")
+	}
+	return 0
+}
+
+func runSearch(args []string) int {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "oc search - manage saved searches")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintln(os.Stderr, "  oc search list")
+		fmt.Fprintln(os.Stderr, "  oc search history")
+		fmt.Fprintln(os.Stderr, "  oc search save <name> <query>")
+		fmt.Fprintln(os.Stderr, "  oc search delete <name>")
+		fmt.Fprintln(os.Stderr, "  oc search run <name> [oc flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Notes:")
+		fmt.Fprintln(os.Stderr, "  - run opens the picker with global search showing the saved query's results")
+		fmt.Fprintln(os.Stderr, "  - saving under an existing name replaces that search; names are case-insensitive")
+		fmt.Fprintln(os.Stderr, "  - searches are kept in searches.json in the oc state dir (see oc --help)")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	if a := args[0]; a == "--help" || a == "-h" || a == "help" {
+		usage()
+		return 0
+	}
+
+	dir, err := state.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot determine state dir: %v\n", err)
+		return 1
+	}
+	searches, err := state.LoadSearches(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot read searches: %v\n", err)
+		return 1
+	}
+	save := func() int {
+		if err := searches.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: cannot save searches: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "list":
+		for _, s := range searches.SavedSearches() {
+			fmt.Fprintf(os.Stdout, "%s\t%s\n", s.Name, s.Query)
+		}
+		return 0
+	case "history":
+		for _, q := range searches.Recent() {
+			fmt.Fprintln(os.Stdout, q)
+		}
+		return 0
+	case "save":
+		if len(rest) < 2 || !searches.SaveSearch(rest[0], strings.Join(rest[1:], " ")) {
+			fmt.Fprintln(os.Stderr, "error: save needs a name and a query")
+			return 2
+		}
+		return save()
+	case "delete":
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, "error: delete needs a name")
+			return 2
+		}
+		if !searches.DeleteSaved(rest[0]) {
+			fmt.Fprintf(os.Stderr, "error: no saved search %q\n", rest[0])
+			return 1
+		}
+		return save()
+	case "run":
+		if len(rest) == 0 {
+			fmt.Fprintln(os.Stderr, "error: run needs a name")
+			return 2
+		}
+		s, ok := searches.Find(rest[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "error: no saved search %q\n", rest[0])
+			return 1
+		}
+		return run(append(append([]string{}, rest[1:]...), "--search", s.Query))
+	}
+	fmt.Fprintf(os.Stderr, "error: unknown search command %q\n", args[0])
+	usage()
+	return 2
+}
+
+// localState is oc's own data read from the state dir.
+type localState struct {
+	dir      string // empty when unknown
+	pins     *state.Pins
+	notes    *state.Notes
+	searches *state.Searches
+	launches []state.Launch
+}
+
+// loadState reads pins, session notes, searches and launch history from the
+// state dir. Failures only warn: the picker works without them.
+func loadState() localState {
+	dir, err := state.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot determine state dir: %v\n", err)
+		return localState{}
+	}
+	st := localState{dir: dir}
+	st.pins, err = state.LoadPins(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot read pins: %v\n", err)
+	}
+	st.notes, err = state.LoadNotes(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot read session notes: %v\n", err)
+	}
+	st.searches, err = state.LoadSearches(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot read searches: %v\n", err)
+	}
+	st.launches, err = state.LoadHistory(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot read launch history: %v\n", err)
+	}
+	return st
+}
+
+func layerStatus(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "missing"
+		}
+		return "unreadable"
+	}
+	return "loaded"
+}
+
 func runUpgrade(args []string) int {
 	for _, a := range args {
 		if a == "--help" || a == "-h" {
@@ -311,7 +703,9 @@ func runUpgrade(args []string) int {
 	return 0
 }
 
-func execOpencode(workDir string, args []string) error {
+// execOpencode runs opencode in workDir with args; env is appended to the
+// current environment for opencode only.
+func execOpencode(workDir string, args []string, env []string) error {
 	opencodePath, err := exec.LookPath("opencode")
 	if err != nil {
 		return err
@@ -335,12 +729,15 @@ func execOpencode(workDir string, args []string) error {
 		return fmt.Errorf("failed to chdir to %s: %w", workDir, err)
 	}
 
+	opencodeEnv := mergeEnv(os.Environ(), env)
+
 	if isSameDir {
 		// Prefer exec-style handoff so the user drops straight into OpenCode.
-		if err := syscall.Exec(opencodePath, append([]string{"opencode"}, args...), os.Environ()); err != nil {
+		if err := syscall.Exec(opencodePath, append([]string{"opencode"}, args...), opencodeEnv); err != nil {
 			// Fallback for environments where Exec isn't supported as expected.
 			cmd := exec.Command(opencodePath, args...)
 			cmd.Dir = workDir
+			cmd.Env = opencodeEnv
 			cmd.Stdin = os.Stdin
 			cmd.Stdout = os.Stdout
 			cmd.Stderr = os.Stderr
@@ -353,6 +750,7 @@ func execOpencode(workDir string, args []string) error {
 	// Scenario B: Launched from a different directory
 	cmd := exec.Command(opencodePath, args...)
 	cmd.Dir = workDir
+	cmd.Env = opencodeEnv
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeEnv_ProfileEnvReplacesInheritedVariables(t *testing.T) {
+	base := []string{"HOME=/home/me", "OPENCODE_CONFIG=/etc/opencode.json", "PATH=/bin"}
+	extra := []string{"DEBUG=1", "OPENCODE_CONFIG=/tmp/profile.json"}
+
+	got := strings.Join(mergeEnv(base, extra), " ")
+	want := "HOME=/home/me PATH=/bin DEBUG=1 OPENCODE_CONFIG=/tmp/profile.json"
+	if got != want {
+		t.Fatalf("mergeEnv = %q, want %q", got, want)
+	}
+}
@@ -1,11 +1,9 @@
 package config
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"io"
-	"os"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,56 +12,257 @@ import (
 type Model struct {
 	Name  string `yaml:"name"`
 	Model string `yaml:"model"`
+	// Hide removes a model defined by an earlier layer or an included file.
+	Hide bool `yaml:"hide"`
+	// Discovered marks models found in OpenCode's config but not listed in
+	// oc-config.yaml.
+	Discovered bool `yaml:"-"`
 }
 
 type UI struct {
-	HideGlobalProjects       bool `yaml:"hide_global_projects"`
-	GlobalSessionsMaxAgeDays int  `yaml:"global_sessions_max_age_days"`
+	HideGlobalProjects       bool  `yaml:"hide_global_projects"`
+	GlobalSessionsMaxAgeDays int   `yaml:"global_sessions_max_age_days"`
+	Theme                    Theme `yaml:"theme"`
+	// Sessions applies to every project's session list; project rules can
+	// override it per field.
+	Sessions SessionPolicy `yaml:"sessions"`
+	// RecentSessions applies to the recent sessions view, falling back to
+	// Sessions per field.
+	RecentSessions SessionPolicy `yaml:"recent_sessions"`
+	// RecentGroup groups the recent sessions view by "date" (default) or
+	// "project".
+	RecentGroup string `yaml:"recent_group"`
+	// Sort picks the initial project and model order.
+	Sort Sort `yaml:"sort"`
+	// Filter is how typing filters the project and session lists: "fuzzy"
+	// (default) or "substring".
+	Filter string `yaml:"filter"`
+	// Mouse enables clicking and wheel scrolling in the TUI.
+	Mouse bool `yaml:"mouse"`
+	// Vim enables modal, vim-style navigation in the TUI.
+	Vim bool `yaml:"vim"`
+	// SearchHistory caps how many recent search queries are kept (default
+	// 100; 0 disables the history).
+	SearchHistory *int `yaml:"search_history"`
+	// Inline renders a compact picker below the prompt instead of using the
+	// full screen; InlineHeight is its height in lines (default 12).
+	Inline       bool `yaml:"inline"`
+	InlineHeight int  `yaml:"inline_height"`
+}
+
+// ProjectRule applies model and display settings to projects whose worktree
+// matches Match.
+//
+// Match is either a path prefix (e.g. ~/work/clients) or, when it contains
+// glob characters, a glob matched against the whole worktree where "**"
+// matches any number of directories. A leading "~" expands to the home
+// directory.
+type ProjectRule struct {
+	Match        string   `yaml:"match"`
+	DefaultModel string   `yaml:"default_model"`
+	Models       []string `yaml:"models"`
+
+	// Name replaces the worktree's base name in the project list.
+	Name string `yaml:"name"`
+	// Aliases are extra short names the project filter matches.
+	Aliases []string `yaml:"aliases"`
+	// Icon is shown before the name; Tag is shown with the path.
+	Icon string `yaml:"icon"`
+	Tag  string `yaml:"tag"`
+	// Hide removes matching projects from the list; a more specific rule
+	// listed earlier can set it to false to keep a project visible.
+	Hide *bool `yaml:"hide"`
+	// Sessions overrides ui.sessions for matching projects.
+	Sessions *SessionPolicy `yaml:"sessions"`
+}
+
+// Profile bundles a model with launch options for OpenCode.
+type Profile struct {
+	Name  string            `yaml:"name"`
+	Model string            `yaml:"model"`
+	Agent string            `yaml:"agent"`
+	Args  []string          `yaml:"args"`
+	Env   map[string]string `yaml:"env"`
+	// Resolved is the configured model Model refers to; set by ForProject.
+	Resolved Model `yaml:"-"`
 }
 
 type Config struct {
-	DefaultModel string  `yaml:"default_model"`
-	Models       []Model `yaml:"models"`
-	UI           UI      `yaml:"ui"`
+	// Include lists files merged before this one, so its own entries win.
+	// Paths are relative to the including file; "~" and globs are expanded.
+	Include      []string `yaml:"include"`
+	DefaultModel string   `yaml:"default_model"`
+	Models       []Model  `yaml:"models"`
+	// ModelOrder moves the named models to the front, in this order. It lets
+	// a later config layer reorder models defined by an earlier one.
+	ModelOrder []string      `yaml:"model_order"`
+	UI         UI            `yaml:"ui"`
+	Projects   []ProjectRule `yaml:"projects"`
+	Discovery  Discovery     `yaml:"discovery"`
+	Profiles   []Profile     `yaml:"profiles"`
+	// Keys rebinds TUI actions (see KeyActions) to lists of keys.
+	Keys map[string][]string `yaml:"keys"`
+
+	// merged is the merged YAML of every loaded layer; sources maps each
+	// value path (e.g. "ui.hide_global_projects", "models[GPT].model") to the
+	// file that set it.
+	merged  *yaml.Node
+	sources map[string]string
+	// discovered holds models found by DiscoverModels so project layers can
+	// re-apply them.
+	discovered []Model
 }
 
+// Load reads a single config file. See LoadLayered for layered configs.
 func Load(path string) (*Config, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+	return LoadLayered([]Layer{{Path: path}})
+}
+
+// problem is a validation finding. path locates it in the merged YAML using
+// indices, e.g. "models[2].model".
+type problem struct {
+	path    string
+	msg     string
+	warning bool
+}
+
+func (c *Config) validate() error {
+	for _, p := range c.problems() {
+		if !p.warning {
+			return errors.New(p.msg)
+		}
 	}
+	return nil
+}
 
-	var cfg Config
-	dec := yaml.NewDecoder(bytes.NewReader(b))
-	dec.KnownFields(true)
-	if err := dec.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("invalid yaml: %w", err)
+// problems returns every error and warning for the merged config. It must run
+// before model_order is applied so indices match the YAML.
+func (c *Config) problems() []problem {
+	var ps []problem
+	add := func(path, format string, args ...any) {
+		ps = append(ps, problem{path: path, msg: fmt.Sprintf(format, args...)})
 	}
-	// Ensure there are no extra YAML documents.
-	var extra any
-	if err := dec.Decode(&extra); err != io.EOF {
-		if err == nil {
-			return nil, errors.New("invalid yaml: multiple documents are not supported")
-		}
-		return nil, fmt.Errorf("invalid yaml: %w", err)
+	warn := func(path, format string, args ...any) {
+		ps = append(ps, problem{path: path, msg: fmt.Sprintf(format, args...), warning: true})
 	}
 
-	if len(cfg.Models) == 0 {
-		return nil, errors.New("no models configured")
+	if len(visibleModels(c.Models)) == 0 {
+		add("models", "no models configured")
 	}
-	if cfg.UI.GlobalSessionsMaxAgeDays < 0 {
-		return nil, fmt.Errorf("ui.global_sessions_max_age_days must be >= 0")
+	if c.UI.GlobalSessionsMaxAgeDays < 0 {
+		add("ui.global_sessions_max_age_days", "ui.global_sessions_max_age_days must be >= 0")
 	}
-	for i, m := range cfg.Models {
+	ids := map[string]int{}
+	for i, m := range c.Models {
+		if m.Hide {
+			if strings.TrimSpace(m.Name) == "" {
+				add(fmt.Sprintf("models[%d]", i), "models[%d].name is required", i)
+			}
+			continue
+		}
 		if strings.TrimSpace(m.Name) == "" {
-			return nil, fmt.Errorf("models[%d].name is required", i)
+			add(fmt.Sprintf("models[%d]", i), "models[%d].name is required", i)
 		}
 		if strings.TrimSpace(m.Model) == "" {
-			return nil, fmt.Errorf("models[%d].model is required", i)
+			add(fmt.Sprintf("models[%d]", i), "models[%d].model is required", i)
+			continue
+		}
+		id := strings.ToLower(strings.TrimSpace(m.Model))
+		if j, ok := ids[id]; ok {
+			warn(fmt.Sprintf("models[%d].model", i), "models[%d].model %q duplicates models[%d]", i, m.Model, j)
+			continue
+		}
+		ids[id] = i
+	}
+	if ref := strings.TrimSpace(c.DefaultModel); ref != "" && len(c.Models) > 0 && !c.Discovery.OpenCode {
+		if _, ok := c.findModel(ref); !ok {
+			add("default_model", "default_model %q does not match any configured model", c.DefaultModel)
 		}
 	}
+	for i, name := range c.ModelOrder {
+		if _, ok := c.findModel(name); !ok {
+			add(fmt.Sprintf("model_order[%d]", i), "model_order[%d] %q does not match any configured model", i, name)
+		}
+	}
+	ps = append(ps, c.projectRuleProblems()...)
+	ps = append(ps, c.profileProblems()...)
+	ps = append(ps, c.sessionProblems()...)
+	ps = append(ps, c.sortProblems()...)
+	ps = append(ps, c.filterProblems()...)
+	ps = append(ps, c.searchProblems()...)
+	ps = append(ps, c.inlineProblems()...)
+	ps = append(ps, c.keyProblems()...)
+	return append(ps, c.themeProblems()...)
+}
 
-	return &cfg, nil
+func (c *Config) projectRuleProblems() []problem {
+	var ps []problem
+	add := func(path, format string, args ...any) {
+		ps = append(ps, problem{path: path, msg: fmt.Sprintf(format, args...)})
+	}
+	// With discovery on, references may name models that only exist in
+	// OpenCode's config; those are resolved per project instead.
+	lenient := c.Discovery.OpenCode
+	for i, r := range c.Projects {
+		if strings.TrimSpace(r.Match) == "" {
+			add(fmt.Sprintf("projects[%d]", i), "projects[%d].match is required", i)
+		}
+		for j, ref := range r.Models {
+			if _, ok := c.findModel(ref); !ok && !lenient {
+				add(fmt.Sprintf("projects[%d].models[%d]", i, j), "projects[%d].models[%d] %q does not match any configured model", i, j, ref)
+			}
+		}
+		if strings.TrimSpace(r.DefaultModel) == "" {
+			continue
+		}
+		path := fmt.Sprintf("projects[%d].default_model", i)
+		def, ok := c.findModel(r.DefaultModel)
+		if !ok {
+			if !lenient {
+				add(path, "projects[%d].default_model %q does not match any configured model", i, r.DefaultModel)
+			}
+			continue
+		}
+		if len(r.Models) > 0 && !containsModel(c.resolveModels(r.Models), def) {
+			add(path, "projects[%d].default_model %q is not in projects[%d].models", i, r.DefaultModel, i)
+		}
+	}
+	return ps
+}
+
+func (c *Config) profileProblems() []problem {
+	var ps []problem
+	add := func(path, format string, args ...any) {
+		ps = append(ps, problem{path: path, msg: fmt.Sprintf(format, args...)})
+	}
+	seen := map[string]int{}
+	for i, p := range c.Profiles {
+		item := fmt.Sprintf("profiles[%d]", i)
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			add(item, "profiles[%d].name is required", i)
+		} else if j, ok := seen[strings.ToLower(name)]; ok {
+			add(item+".name", "profiles[%d].name %q duplicates profiles[%d]", i, p.Name, j)
+		} else {
+			seen[strings.ToLower(name)] = i
+		}
+		if strings.TrimSpace(p.Model) == "" {
+			add(item, "profiles[%d].model is required", i)
+		} else if _, ok := c.findModel(p.Model); !ok && !c.Discovery.OpenCode {
+			add(item+".model", "profiles[%d].model %q does not match any configured model", i, p.Model)
+		}
+		keys := make([]string, 0, len(p.Env))
+		for k := range p.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if strings.TrimSpace(k) == "" || strings.Contains(k, "=") {
+				add(item+".env", "profiles[%d].env has invalid variable name %q", i, k)
+			}
+		}
+	}
+	return ps
 }
 
 func (c *Config) Default() (Model, error) {
@@ -73,19 +272,68 @@ func (c *Config) Default() (Model, error) {
 	if strings.TrimSpace(c.DefaultModel) == "" {
 		return c.Models[0], nil
 	}
+	if m, ok := c.findModel(c.DefaultModel); ok {
+		return m, nil
+	}
+	return Model{}, fmt.Errorf("default_model %q does not match any configured model", c.DefaultModel)
+}
 
-	needle := strings.ToLower(strings.TrimSpace(c.DefaultModel))
+// findModel looks up a model by name, then by model ID (case-insensitive).
+// Hidden models never match.
+func (c *Config) findModel(ref string) (Model, bool) {
+	needle := strings.ToLower(strings.TrimSpace(ref))
+	if needle == "" {
+		return Model{}, false
+	}
 	for _, m := range c.Models {
-		if strings.ToLower(m.Name) == needle {
-			return m, nil
+		if !m.Hide && strings.ToLower(m.Name) == needle {
+			return m, true
 		}
 	}
 	for _, m := range c.Models {
-		if strings.ToLower(m.Model) == needle {
-			return m, nil
+		if !m.Hide && strings.ToLower(m.Model) == needle {
+			return m, true
 		}
 	}
-	return Model{}, fmt.Errorf("default_model %q does not match any configured model", c.DefaultModel)
+	return Model{}, false
+}
+
+// resolveModels maps model references to configured models, keeping the
+// configured order and dropping unknown references.
+func (c *Config) resolveModels(refs []string) []Model {
+	want := make([]Model, 0, len(refs))
+	for _, ref := range refs {
+		if m, ok := c.findModel(ref); ok {
+			want = append(want, m)
+		}
+	}
+	out := make([]Model, 0, len(want))
+	for _, m := range c.Models {
+		if containsModel(want, m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// visibleModels drops models marked hide.
+func visibleModels(models []Model) []Model {
+	out := make([]Model, 0, len(models))
+	for _, m := range models {
+		if !m.Hide {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func containsModel(models []Model, m Model) bool {
+	for _, x := range models {
+		if x.Name == m.Name && x.Model == m.Model {
+			return true
+		}
+	}
+	return false
 }
 
 func MinimalExampleYAML() string {
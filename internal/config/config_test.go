@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +61,680 @@ models:
 		t.Fatalf("expected error for unknown fields")
 	}
 }
+
+func TestForProject_AppliesFirstMatchingRule(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+
+	if err := os.WriteFile(p, []byte(`
+default_model: GPT-5.2
+models:
+  - name: Gemini Pro
+    model: google/gemini-pro
+  - name: GPT-5.2
+    model: openai/gpt-5.2
+  - name: Claude
+    model: anthropic/claude
+projects:
+  - match: /work/clients/**/api
+    default_model: Claude
+  - match: /work/clients
+    models: [Claude, google/gemini-pro]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pm := mustForProject(t, cfg, "/work/clients/acme/api")
+	if !pm.Matched || pm.Default.Name != "Claude" {
+		t.Fatalf("expected Claude default for client api, got %+v", pm)
+	}
+	if len(pm.Models) != 2 || pm.Models[0].Name != "Gemini Pro" || pm.Models[1].Name != "Claude" {
+		t.Fatalf("expected allowed subset in configured order, got %+v", pm.Models)
+	}
+
+	pm = mustForProject(t, cfg, "/work/clients/acme/web")
+	if pm.Default.Name != "Gemini Pro" {
+		t.Fatalf("expected first allowed model when global default is not allowed, got %+v", pm.Default)
+	}
+
+	pm = mustForProject(t, cfg, "/work/clientsX/foo")
+	if pm.Matched || pm.Default.Name != "GPT-5.2" || len(pm.Models) != 3 {
+		t.Fatalf("expected global models for unmatched project, got %+v", pm)
+	}
+}
+
+func TestLoad_RejectsUnknownProjectRuleModel(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT-5.2
+    model: openai/gpt-5.2
+projects:
+  - match: ~/work
+    default_model: Nope
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(p); err == nil {
+		t.Fatalf("expected error for unknown project default_model")
+	}
+}
+
+func TestDiscoverModels_MergesOpenCodeConfig(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("OPENCODE_CONFIG", "")
+	if err := os.MkdirAll(filepath.Join(xdg, "opencode"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(xdg, "opencode", "opencode.jsonc"), []byte(`{
+  // global OpenCode config
+  "model": "openai/gpt-5.2",
+  "provider": {
+    "anthropic": {
+      "models": {
+        "claude-x": { "name": "Claude X" }, /* trailing comma below */
+      },
+    },
+  },
+}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(worktree, "opencode.json"), []byte(`{"model":"local/llama"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{
+		DefaultModel: "GPT",
+		Models:       []Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Discovery:    Discovery{OpenCode: true},
+	}
+	if err := cfg.DiscoverModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Models) != 2 {
+		t.Fatalf("expected curated + 1 discovered model, got %+v", cfg.Models)
+	}
+	if cfg.Models[0].Discovered || cfg.Models[0].Name != "GPT" {
+		t.Fatalf("expected curated model to stay first and unmarked, got %+v", cfg.Models[0])
+	}
+	if got := cfg.Models[1]; !got.Discovered || got.Name != "Claude X" || got.Model != "anthropic/claude-x" {
+		t.Fatalf("unexpected discovered model: %+v", got)
+	}
+
+	pm := mustForProject(t, cfg, worktree)
+	if len(pm.Models) != 3 || pm.Models[2].Model != "local/llama" || pm.Default.Name != "GPT" {
+		t.Fatalf("expected project-level discovery with curated default, got %+v", pm)
+	}
+}
+
+func TestForProject_ResolvesProfilesWithinAllowedModels(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: Cheap
+    model: openai/gpt-mini
+  - name: Strong
+    model: anthropic/claude
+profiles:
+  - name: review
+    model: Cheap
+    agent: plan
+  - name: build
+    model: anthropic/claude
+    agent: build
+    args: [--print-logs]
+    env:
+      OPENCODE_EXPERIMENTAL: "1"
+projects:
+  - match: /clients
+    models: [Strong]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pm := mustForProject(t, cfg, "/home/me/personal")
+	if len(pm.Profiles) != 2 || pm.Profiles[0].Resolved.Name != "Cheap" || pm.Profiles[1].Resolved.Name != "Strong" {
+		t.Fatalf("expected both profiles resolved, got %+v", pm.Profiles)
+	}
+	if pm.Profiles[1].Env["OPENCODE_EXPERIMENTAL"] != "1" || pm.Profiles[1].Args[0] != "--print-logs" {
+		t.Fatalf("expected profile args/env to be decoded, got %+v", pm.Profiles[1])
+	}
+
+	pm = mustForProject(t, cfg, "/clients/acme")
+	if len(pm.Profiles) != 1 || pm.Profiles[0].Name != "build" {
+		t.Fatalf("expected only profiles with allowed models, got %+v", pm.Profiles)
+	}
+}
+
+func mustForProject(t *testing.T, cfg *Config, worktree string) ProjectModels {
+	t.Helper()
+	pm, err := cfg.ForProject(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pm
+}
+
+func TestLoadLayered_MergesSystemUserAndProject(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.yaml")
+	user := filepath.Join(dir, "user.yaml")
+	if err := os.WriteFile(system, []byte(`
+default_model: Cheap
+models:
+  - name: Cheap
+    model: openai/gpt-mini
+  - name: Strong
+    model: anthropic/claude
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(user, []byte(`
+default_model: Strong
+models:
+  - name: Cheap
+    model: openai/gpt-nano
+  - name: Local
+    model: ollama/llama
+model_order: [Local]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadLayered([]Layer{{Path: filepath.Join(dir, "missing.yaml"), Optional: true}, {Path: system, Optional: true}, {Path: user}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Models) != 3 || cfg.Models[0].Name != "Local" || cfg.Models[1].Name != "Cheap" || cfg.Models[2].Name != "Strong" {
+		t.Fatalf("expected keyed merge reordered by model_order, got %+v", cfg.Models)
+	}
+	if cfg.Models[1].Model != "openai/gpt-nano" {
+		t.Fatalf("expected user layer to override Cheap, got %+v", cfg.Models[1])
+	}
+	if def, _ := cfg.Default(); def.Name != "Strong" {
+		t.Fatalf("expected user default, got %+v", def)
+	}
+	if got := cfg.Source("default_model"); got != user {
+		t.Fatalf("expected default_model from user layer, got %q", got)
+	}
+	if got := cfg.Source("models[Strong].model"); got != system {
+		t.Fatalf("expected Strong from system layer, got %q", got)
+	}
+	out, err := cfg.AnnotatedYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "# "+system) || !strings.Contains(string(out), "# "+user) {
+		t.Fatalf("expected source annotations, got:\n%s", out)
+	}
+
+	project := filepath.Join(dir, "proj")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ProjectConfigName), []byte("default_model: Local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if pm := mustForProject(t, cfg, project); pm.Default.Name != "Local" {
+		t.Fatalf("expected project default, got %+v", pm.Default)
+	}
+
+	if err := os.WriteFile(filepath.Join(project, ProjectConfigName), []byte("ui:\n  hide_global_projects: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.WithProject(project); err == nil || !strings.Contains(err.Error(), `"ui" is not supported`) {
+		t.Fatalf("expected ui key to be rejected in project config, got %v", err)
+	}
+
+	// A cloned repository must not override profiles or pull in other files.
+	for _, key := range []string{"profiles", "include"} {
+		body := "profiles:\n  - name: Work\n    model: GPT\n    env:\n      EVIL: \"1\"\n"
+		if key == "include" {
+			body = "include:\n  - ~/.ssh/*.yaml\n"
+		}
+		if err := os.WriteFile(filepath.Join(project, ProjectConfigName), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cfg.WithProject(project); err == nil || !strings.Contains(err.Error(), `"`+key+`" is not supported`) {
+			t.Fatalf("expected %s to be rejected in project config, got %v", key, err)
+		}
+	}
+}
+
+func TestValidate_ReportsAllProblemsWithPositions(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`default_model: Missing
+ui:
+  hide_global_projects: maybe
+  colour: blue
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+  - name: gpt
+    model: openai/gpt-5.1
+  - name: Other
+    model: openai/gpt-5.2
+  - name: Broken
+profiles:
+  - name: review
+    model: Nope
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diags := Validate([]Layer{{Path: p}}, "")
+	var got []string
+	for _, d := range diags {
+		got = append(got, strings.TrimPrefix(d.String(), p+":"))
+	}
+	want := []string{
+		`1:16: error: default_model "Missing" does not match any configured model`,
+		`3:25: error: ui.hide_global_projects: cannot use "maybe" as a boolean`,
+		`4:3: error: ui: unknown field "colour"`,
+		`8:11: warning: models[1].name "gpt" duplicates models[0]`,
+		`11:12: warning: models[2].model "openai/gpt-5.2" duplicates models[0]`,
+		`12:5: error: models[3].model is required`,
+		`15:12: error: profiles[0].model "Nope" does not match any configured model`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !HasErrors(diags) {
+		t.Fatalf("expected errors")
+	}
+}
+
+func TestValidate_PointsAtLayerThatSetTheValue(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.yaml")
+	user := filepath.Join(dir, "user.yaml")
+	if err := os.WriteFile(system, []byte("models:\n  - name: GPT\n    model: openai/gpt-5.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(user, []byte("\ndefault_model: Claude\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := Validate([]Layer{{Path: system}, {Path: user}}, "")
+	if len(diags) != 1 || diags[0].File != user || diags[0].Line != 2 || diags[0].Column != 16 {
+		t.Fatalf("expected one diagnostic at %s:2:16, got %+v", user, diags)
+	}
+}
+
+func TestJSONSchema_CoversConfigFields(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(JSONSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	defs, _ := schema["definitions"].(map[string]any)
+	var check func(t *testing.T, node map[string]any, typ reflect.Type, path string)
+	check = func(t *testing.T, node map[string]any, typ reflect.Type, path string) {
+		if ref, ok := node["$ref"].(string); ok {
+			node, _ = defs[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		}
+		switch typ.Kind() {
+		case reflect.Slice:
+			items, _ := node["items"].(map[string]any)
+			check(t, items, typ.Elem(), path+"[]")
+		case reflect.Struct:
+			props, _ := node["properties"].(map[string]any)
+			for i := 0; i < typ.NumField(); i++ {
+				f := typ.Field(i)
+				name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+				if !f.IsExported() || name == "-" {
+					continue
+				}
+				sub, ok := props[name].(map[string]any)
+				if !ok {
+					t.Errorf("schema is missing %s", joinPath(path, name))
+					continue
+				}
+				check(t, sub, f.Type, joinPath(path, name))
+			}
+			if len(props) != typ.NumField() {
+				for name := range props {
+					if _, ok := yamlField(typ, name); !ok {
+						t.Errorf("schema has unknown property %s", joinPath(path, name))
+					}
+				}
+			}
+		}
+	}
+	check(t, schema, reflect.TypeOf(Config{}), "")
+
+	props, _ := schema["properties"].(map[string]any)
+	keys, _ := props["keys"].(map[string]any)
+	actions, _ := keys["properties"].(map[string]any)
+	if len(actions) != len(KeyActions) {
+		t.Errorf("schema lists %d key actions, want %d", len(actions), len(KeyActions))
+	}
+	for _, a := range KeyActions {
+		if _, ok := actions[a.Name]; !ok {
+			t.Errorf("schema is missing keys.%s", a.Name)
+		}
+	}
+}
+
+func TestKeys_RejectsUnknownActionsAndWarnsOnConflicts(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+keys:
+  serach: [ctrl+k]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(p); err == nil || !strings.Contains(err.Error(), "keys.serach is not a known action") {
+		t.Fatalf("expected unknown action error, got %v", err)
+	}
+
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+keys:
+  search: [ctrl+r, alt+s]
+  recent: []
+  quit: [ctrl+right]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kb := cfg.KeyBindings()
+	if strings.Join(kb["search"], ",") != "ctrl+r,alt+s" || len(kb["recent"]) != 0 || kb["launch"][0] != "enter" {
+		t.Fatalf("unexpected effective bindings: %v", kb)
+	}
+	diags := Validate([]Layer{{Path: p}}, "")
+	if len(diags) != 1 || diags[0].Severity != SeverityWarning || !strings.Contains(diags[0].Message, `"ctrl+right" is bound to both quit and next_match in the search view`) {
+		t.Fatalf("expected one conflict warning, got %+v", diags)
+	}
+}
+
+func TestKeys_KeepCaseOfKeyNames(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+keys:
+  pin: [" G "]
+  edit_note: [g]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kb := cfg.KeyBindings()
+	if strings.Join(kb["pin"], ",") != "G" || strings.Join(kb["edit_note"], ",") != "g" {
+		t.Fatalf("expected trimmed keys with their case, got %v / %v", kb["pin"], kb["edit_note"])
+	}
+	if diags := Validate([]Layer{{Path: p}}, ""); len(diags) != 0 {
+		t.Fatalf("expected G and g not to conflict, got %+v", diags)
+	}
+}
+
+func TestLoad_ValidatesTheme(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+ui:
+  theme:
+    name: solarized
+    roles:
+      accent: pink
+      border: "#333"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := Validate([]Layer{{Path: p}}, "")
+	var msgs []string
+	for _, d := range diags {
+		msgs = append(msgs, d.Message)
+	}
+	want := []string{
+		`ui.theme.name "solarized" is not one of auto, dark, light, high-contrast`,
+		`ui.theme.roles.accent "pink" is not a color (use 0-255 or #RRGGBB)`,
+	}
+	if strings.Join(msgs, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected diagnostics:\n%s", strings.Join(msgs, "\n"))
+	}
+}
+
+func TestLoad_IncludesInterpolatesAndHides(t *testing.T) {
+	dir := t.TempDir()
+	team := filepath.Join(dir, "team")
+	if err := os.MkdirAll(team, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(team, "a-openai.yaml"), []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+  - name: Mini
+    model: openai/gpt-mini
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(team, "b-anthropic.yaml"), []byte(`
+models:
+  - name: Claude
+    model: ${OC_TEST_PROVIDER:-anthropic}/claude
+ui:
+  global_sessions_max_age_days: ${OC_TEST_DAYS}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+include: [team/*.yaml]
+default_model: Claude
+models:
+  - name: GPT
+    model: openai/gpt-5.2-${OC_TEST_SUFFIX}
+  - name: Mini
+    hide: true
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OC_TEST_DAYS", "14")
+	t.Setenv("OC_TEST_SUFFIX", "pro")
+
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range cfg.Models {
+		got = append(got, m.Name+"="+m.Model)
+	}
+	if strings.Join(got, ",") != "GPT=openai/gpt-5.2-pro,Claude=anthropic/claude" {
+		t.Fatalf("unexpected models: %v", got)
+	}
+	if cfg.UI.GlobalSessionsMaxAgeDays != 14 {
+		t.Fatalf("expected interpolated int, got %d", cfg.UI.GlobalSessionsMaxAgeDays)
+	}
+	if got := cfg.Source("models[Claude].model"); got != filepath.Join(team, "b-anthropic.yaml") {
+		t.Fatalf("expected Claude sourced from included file, got %q", got)
+	}
+
+	os.Unsetenv("OC_TEST_SUFFIX")
+	if _, err := Load(p); err == nil || !strings.Contains(err.Error(), "oc-config.yaml:6:12: environment variable OC_TEST_SUFFIX is not set") {
+		t.Fatalf("expected unset variable error with position, got %v", err)
+	}
+}
+
+func TestLoad_DetectsIncludeCycles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	if err := os.WriteFile(a, []byte("include: [b.yaml]\nmodels:\n  - name: GPT\n    model: openai/gpt-5.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("include: [./a.yaml]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(a)
+	if err == nil || !strings.Contains(err.Error(), "include cycle: "+a+" -> "+b+" -> "+a) {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestProjectDisplay_FirstMatchingRuleWinsPerField(t *testing.T) {
+	hide, show := true, false
+	cfg := &Config{Projects: []ProjectRule{
+		{Match: "/src/keep", Hide: &show, Name: "Keeper"},
+		{Match: "/src/*/api", Name: "API", Aliases: []string{"a"}, Icon: "*"},
+		{Match: "/src", Tag: "work", Hide: &hide},
+	}}
+
+	d := cfg.ProjectDisplay("/src/acme/api")
+	if d.Name != "API" || d.Icon != "*" || d.Tag != "work" || !d.Hide || len(d.Aliases) != 1 {
+		t.Fatalf("unexpected display: %+v", d)
+	}
+	if d := cfg.ProjectDisplay("/src/keep"); d.Hide || d.Name != "Keeper" || d.Tag != "work" {
+		t.Fatalf("expected earlier rule to keep project visible, got %+v", d)
+	}
+	if d := cfg.ProjectDisplay("/elsewhere/api"); d.Name != "" || d.Hide {
+		t.Fatalf("expected no display settings, got %+v", d)
+	}
+	if pm := mustForProject(t, cfg, "/src/acme/api"); pm.Matched {
+		t.Fatalf("expected display-only rules not to count as model rules")
+	}
+}
+
+func TestSessionLimits_ResolvePerFieldWithFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+ui:
+  sessions:
+    max_age_days: 30
+    min_messages: 1
+  recent_sessions:
+    max_count: 50
+projects:
+  - match: /src/scratch
+    sessions:
+      max_count: 5
+  - match: /src
+    sessions:
+      max_age_days: 0
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.ProjectSessionLimits("/src/scratch"); got != (SessionLimits{MaxCount: 5, MinMessages: 1, MaxAgeSet: true}) {
+		t.Fatalf("unexpected scratch limits: %+v", got)
+	}
+	if got := cfg.ProjectSessionLimits("/elsewhere"); got != (SessionLimits{MaxAgeDays: 30, MinMessages: 1, MaxAgeSet: true}) {
+		t.Fatalf("unexpected default limits: %+v", got)
+	}
+	if got := cfg.RecentSessionLimits(); got != (SessionLimits{MaxAgeDays: 30, MaxCount: 50, MinMessages: 1, MaxAgeSet: true}) {
+		t.Fatalf("unexpected recent limits: %+v", got)
+	}
+	if !cfg.CountsMessages() || (&Config{}).CountsMessages() {
+		t.Fatal("expected message counts to be needed only with a min_messages policy")
+	}
+
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+ui:
+  recent_sessions:
+    max_count: -1
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := Validate([]Layer{{Path: p}}, "")
+	if len(diags) != 1 || diags[0].Message != "ui.recent_sessions.max_count must be >= 0" || diags[0].Line != 7 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestSearchHistory_DefaultsAndRejectsNegative(t *testing.T) {
+	if got := (UI{}).SearchHistoryLimit(); got != DefaultSearchHistory {
+		t.Fatalf("expected the default history cap, got %d", got)
+	}
+	zero := 0
+	if got := (UI{SearchHistory: &zero}).SearchHistoryLimit(); got != 0 {
+		t.Fatalf("expected 0 to disable the history, got %d", got)
+	}
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+ui:
+  search_history: -1
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := Validate([]Layer{{Path: p}}, "")
+	if len(diags) != 1 || diags[0].Message != "ui.search_history must be >= 0" || diags[0].Line != 6 {
+		t.Fatalf("unexpected diagnostics: %+v", diags)
+	}
+}
+
+func TestInlineHeight_DefaultsAndRejectsNegative(t *testing.T) {
+	if got := (UI{}).InlineLines(); got != DefaultInlineHeight {
+		t.Fatalf("expected the default inline height, got %d", got)
+	}
+	if got := (UI{InlineHeight: 8}).InlineLines(); got != 8 {
+		t.Fatalf("expected 8, got %d", got)
+	}
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "oc-config.yaml")
+	if err := os.WriteFile(p, []byte(`
+models:
+  - name: GPT
+    model: openai/gpt-5.2
+ui:
+  inline: true
+  inline_height: -3
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := Validate([]Layer{{Path: p}}, "")
+	if len(diags) != 1 || diags[0].Message != "ui.inline_height must be >= 0" || diags[0].Line != 7 {
+		t.Fatalf("unexpected diagnostics: %+v", diags)
+	}
+}
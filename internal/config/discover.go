@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Discovery controls optional model discovery from other tools' configs.
+type Discovery struct {
+	// OpenCode reads provider/model entries from OpenCode's opencode.json(c).
+	OpenCode bool `yaml:"opencode"`
+}
+
+// openCodeConfigNames are the file names OpenCode reads, in priority order.
+var openCodeConfigNames = []string{"opencode.jsonc", "opencode.json"}
+
+// DiscoverModels merges models from OpenCode's global configuration into the
+// configured list when discovery is enabled. Curated models keep their order,
+// names and default; discovered-only models are appended and marked.
+func (c *Config) DiscoverModels() error {
+	if c == nil || !c.Discovery.OpenCode {
+		return nil
+	}
+	found, err := discoverOpenCodeModels(openCodeGlobalConfigPaths())
+	c.discovered = found
+	c.Models = mergeDiscovered(c.Models, found)
+	return err
+}
+
+// projectDiscoveredModels returns the models list extended with models from
+// the project's own OpenCode config. Unreadable files are ignored; the picker
+// should not fail over another tool's config.
+func (c *Config) projectDiscoveredModels(worktree string) []Model {
+	if !c.Discovery.OpenCode || strings.TrimSpace(worktree) == "" {
+		return c.Models
+	}
+	paths := make([]string, 0, 2*len(openCodeConfigNames))
+	for _, name := range openCodeConfigNames {
+		paths = append(paths, filepath.Join(worktree, name))
+		paths = append(paths, filepath.Join(worktree, ".opencode", name))
+	}
+	found, _ := discoverOpenCodeModels(paths)
+	return mergeDiscovered(c.Models, found)
+}
+
+func openCodeGlobalConfigPaths() []string {
+	paths := []string{}
+	if p := strings.TrimSpace(os.Getenv("OPENCODE_CONFIG")); p != "" {
+		paths = append(paths, p)
+	}
+	dir := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME"))
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return paths
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	for _, name := range openCodeConfigNames {
+		paths = append(paths, filepath.Join(dir, "opencode", name))
+	}
+	return paths
+}
+
+// discoverOpenCodeModels reads every existing path and returns the models
+// they mention, de-duplicated by model ID in discovery order.
+func discoverOpenCodeModels(paths []string) ([]Model, error) {
+	var out []Model
+	seen := map[string]struct{}{}
+	var errs []string
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		models, err := parseOpenCodeConfig(b)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", p, err))
+			continue
+		}
+		for _, m := range models {
+			if _, ok := seen[m.Model]; ok {
+				continue
+			}
+			seen[m.Model] = struct{}{}
+			out = append(out, m)
+		}
+	}
+	if len(errs) > 0 {
+		return out, fmt.Errorf("opencode model discovery: %s", strings.Join(errs, "; "))
+	}
+	return out, nil
+}
+
+func parseOpenCodeConfig(b []byte) ([]Model, error) {
+	var raw struct {
+		Model      string `json:"model"`
+		SmallModel string `json:"small_model"`
+		Provider   map[string]struct {
+			Models map[string]struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		} `json:"provider"`
+	}
+	if err := json.Unmarshal(stripJSONC(b), &raw); err != nil {
+		return nil, err
+	}
+
+	out := []Model{}
+	for _, id := range []string{raw.Model, raw.SmallModel} {
+		if id = strings.TrimSpace(id); id != "" {
+			out = append(out, Model{Name: id, Model: id, Discovered: true})
+		}
+	}
+	providers := make([]string, 0, len(raw.Provider))
+	for p := range raw.Provider {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	for _, p := range providers {
+		ids := make([]string, 0, len(raw.Provider[p].Models))
+		for id := range raw.Provider[p].Models {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			full := strings.TrimSpace(p) + "/" + strings.TrimSpace(id)
+			name := strings.TrimSpace(raw.Provider[p].Models[id].Name)
+			if name == "" {
+				name = full
+			}
+			out = append(out, Model{Name: name, Model: full, Discovered: true})
+		}
+	}
+	return out, nil
+}
+
+// mergeDiscovered appends discovered models whose ID is not already curated.
+func mergeDiscovered(curated, found []Model) []Model {
+	if len(found) == 0 {
+		return curated
+	}
+	out := make([]Model, 0, len(curated)+len(found))
+	out = append(out, curated...)
+	ids := make(map[string]struct{}, len(out))
+	names := make(map[string]struct{}, len(out))
+	for _, m := range curated {
+		ids[strings.ToLower(m.Model)] = struct{}{}
+		names[strings.ToLower(m.Name)] = struct{}{}
+	}
+	for _, m := range found {
+		if _, ok := ids[strings.ToLower(m.Model)]; ok {
+			continue
+		}
+		ids[strings.ToLower(m.Model)] = struct{}{}
+		// Never shadow a curated name; fall back to the ID.
+		if _, ok := names[strings.ToLower(m.Name)]; ok {
+			m.Name = m.Model
+		}
+		names[strings.ToLower(m.Name)] = struct{}{}
+		out = append(out, m)
+	}
+	return out
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas so JSONC can be
+// decoded with encoding/json.
+func stripJSONC(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	inString := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(b) {
+				i++
+				out = append(out, b[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			if i < len(b) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i+1 < len(b) && !(b[i] == '*' && b[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ',':
+			// Drop the comma if only whitespace/comments separate it from a closer.
+			if j := skipJSONCSpace(b, i+1); j < len(b) && (b[j] == '}' || b[j] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// skipJSONCSpace returns the index of the first byte at or after i that is not
+// whitespace or part of a comment.
+func skipJSONCSpace(b []byte, i int) int {
+	for i < len(b) {
+		switch {
+		case b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r':
+			i++
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i+1 < len(b) && !(b[i] == '*' && b[i+1] == '/') {
+				i++
+			}
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
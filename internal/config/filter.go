@@ -0,0 +1,23 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter modes for ui.filter.
+const (
+	FilterFuzzy     = "fuzzy"     // fuzzy matching ranked by score (default)
+	FilterSubstring = "substring" // case-insensitive substring matching
+)
+
+// SubstringFilter reports whether lists filter by substring instead of
+// fuzzy matching.
+func (u UI) SubstringFilter() bool { return strings.TrimSpace(u.Filter) == FilterSubstring }
+
+func (c *Config) filterProblems() []problem {
+	if v := strings.TrimSpace(c.UI.Filter); v != "" && v != FilterFuzzy && v != FilterSubstring {
+		return []problem{{path: "ui.filter", msg: fmt.Sprintf("ui.filter %q is not one of %s, %s", c.UI.Filter, FilterFuzzy, FilterSubstring)}}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layerDoc is one parsed config file. A layer expands to its included files
+// followed by the file itself, so local entries override included ones.
+type layerDoc struct {
+	path  string
+	doc   *yaml.Node // nil when the file could not be parsed
+	diags []Diagnostic
+}
+
+// expandLayer reads path and, depth first, every file it includes. Only a
+// failure to read path itself is returned as an error; everything else is
+// reported as diagnostics on the file concerned.
+func expandLayer(path string, project bool) ([]layerDoc, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []layerDoc
+	expandInto(&out, path, b, project, nil)
+	return out, nil
+}
+
+func expandInto(out *[]layerDoc, path string, b []byte, project bool, stack []string) {
+	doc, diags := parseLayer(path, b, project)
+	stack = append(stack, absPath(path))
+	// A project layer may not include files; parseLayer reports the key.
+	if doc != nil && !project {
+		for _, inc := range takeIncludes(doc) {
+			targets, ds := resolveInclude(path, inc)
+			diags = append(diags, ds...)
+			for _, target := range targets {
+				if i := indexOf(stack, absPath(target)); i >= 0 {
+					chain := append(append([]string(nil), stack[i:]...), absPath(target))
+					diags = append(diags, nodeDiag(path, inc, "include cycle: "+strings.Join(chain, " -> ")))
+					continue
+				}
+				tb, err := os.ReadFile(target)
+				if err != nil {
+					diags = append(diags, nodeDiag(path, inc, "include: "+err.Error()))
+					continue
+				}
+				expandInto(out, target, tb, project, stack)
+			}
+		}
+	}
+	*out = append(*out, layerDoc{path: path, doc: doc, diags: diags})
+}
+
+// takeIncludes removes the include key from doc and returns its entries.
+func takeIncludes(doc *yaml.Node) []*yaml.Node {
+	root := docRoot(doc)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	i := mappingIndex(root, "include")
+	if i < 0 {
+		return nil
+	}
+	v := root.Content[i+1]
+	root.Content = append(root.Content[:i], root.Content[i+2:]...)
+	if v.Kind != yaml.SequenceNode {
+		// Already reported by the structure check.
+		return nil
+	}
+	return v.Content
+}
+
+// resolveInclude expands one include entry relative to the including file.
+// "~" expands to the home directory; globs may match nothing.
+func resolveInclude(from string, inc *yaml.Node) ([]string, []Diagnostic) {
+	p := expandHome(strings.TrimSpace(inc.Value))
+	if p == "" {
+		return nil, []Diagnostic{nodeDiag(from, inc, "include path is empty")}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(from), p)
+	}
+	if !strings.ContainsAny(p, "*?[") {
+		return []string{p}, nil
+	}
+	matches, err := filepath.Glob(p)
+	if err != nil {
+		return nil, []Diagnostic{nodeDiag(from, inc, fmt.Sprintf("include %q: %v", inc.Value, err))}
+	}
+	return matches, nil
+}
+
+func nodeDiag(path string, n *yaml.Node, msg string) Diagnostic {
+	return Diagnostic{File: path, Line: n.Line, Column: n.Column, Message: msg}
+}
+
+func absPath(p string) string {
+	if a, err := filepath.Abs(p); err == nil {
+		return a
+	}
+	return filepath.Clean(p)
+}
+
+func indexOf(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// interpolate replaces ${VAR} and ${VAR:-default} in scalar values (not
+// keys) with environment variables. "$${" produces a literal "${".
+func interpolate(n *yaml.Node, report func(*yaml.Node, Severity, string, ...any)) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			interpolate(c, report)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			interpolate(n.Content[i+1], report)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "${") {
+			return
+		}
+		v, err := expandEnv(n.Value)
+		if err != nil {
+			report(n, SeverityError, "%v", err)
+		}
+		n.Value = v
+		if n.Style == 0 {
+			// Re-resolve plain scalars so "${DAYS}" can fill an int field.
+			n.Tag = ""
+		}
+	}
+}
+
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	var errs []string
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			b.WriteString(s)
+			errs = append(errs, "unterminated ${")
+			break
+		}
+		b.WriteString(s[:i])
+		expr := s[i+2 : i+end]
+		name, def, hasDef := strings.Cut(expr, ":-")
+		if val, ok := os.LookupEnv(name); ok && (val != "" || !hasDef) {
+			b.WriteString(val)
+		} else if hasDef {
+			b.WriteString(def)
+		} else if !validEnvName(name) {
+			errs = append(errs, fmt.Sprintf("invalid variable reference ${%s}", expr))
+		} else {
+			errs = append(errs, fmt.Sprintf("environment variable %s is not set", name))
+		}
+		s = s[i+end+1:]
+	}
+	if len(errs) > 0 {
+		return b.String(), errors.New(strings.Join(errs, "; "))
+	}
+	return b.String(), nil
+}
+
+func validEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package config
+
+// DefaultInlineHeight is the height of the inline picker when
+// ui.inline_height is not set.
+const DefaultInlineHeight = 12
+
+// InlineLines is the height of the inline picker in lines.
+func (u UI) InlineLines() int {
+	if u.InlineHeight == 0 {
+		return DefaultInlineHeight
+	}
+	return u.InlineHeight
+}
+
+func (c *Config) inlineProblems() []problem {
+	if c.UI.InlineHeight < 0 {
+		return []problem{{path: "ui.inline_height", msg: "ui.inline_height must be >= 0"}}
+	}
+	return nil
+}
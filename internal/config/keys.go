@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key scopes: the views in which an action is active.
+const (
+	KeyScopeProjects = "projects"
+	KeyScopeRecent   = "recent"
+	KeyScopeSearch   = "search"
+)
+
+// KeyAction is an action that can be rebound under keys:.
+type KeyAction struct {
+	Name   string
+	Keys   []string
+	Help   string
+	Scopes []string
+}
+
+// KeyActions lists every rebindable action with its default keys. Key names
+// use Bubble Tea's notation, e.g. "ctrl+f", "alt+f", "shift+tab", "enter".
+var KeyActions = []KeyAction{
+	{Name: "quit", Keys: []string{"ctrl+c"}, Help: "quit without launching", Scopes: []string{KeyScopeProjects, KeyScopeRecent, KeyScopeSearch}},
+	{Name: "launch", Keys: []string{"enter"}, Help: "launch the selection", Scopes: []string{KeyScopeProjects, KeyScopeRecent, KeyScopeSearch}},
+	{Name: "back", Keys: []string{"esc"}, Help: "close recent sessions or search", Scopes: []string{KeyScopeRecent, KeyScopeSearch}},
+	{Name: "help", Keys: []string{"?"}, Help: "show every key binding of the current view", Scopes: []string{KeyScopeProjects, KeyScopeRecent, KeyScopeSearch}},
+	{Name: "search", Keys: []string{"ctrl+f", "alt+f", "meta+f", "cmd+f"}, Help: "open or close global search", Scopes: []string{KeyScopeProjects, KeyScopeRecent, KeyScopeSearch}},
+	{Name: "recent", Keys: []string{"ctrl+r"}, Help: "toggle recent sessions", Scopes: []string{KeyScopeProjects, KeyScopeRecent}},
+	{Name: "projects", Keys: []string{"ctrl+p"}, Help: "back to projects from recent sessions", Scopes: []string{KeyScopeRecent}},
+	{Name: "sort", Keys: []string{"ctrl+o"}, Help: "toggle frecency sort for the projects or model column", Scopes: []string{KeyScopeProjects}},
+	{Name: "pin", Keys: []string{"alt+p"}, Help: "pin or unpin the selected project or session", Scopes: []string{KeyScopeProjects, KeyScopeRecent}},
+	{Name: "edit_note", Keys: []string{"alt+e"}, Help: "edit the tags and note of the selected session", Scopes: []string{KeyScopeProjects, KeyScopeRecent}},
+	{Name: "collapse_older", Keys: []string{"ctrl+g"}, Help: "collapse or expand the older date groups of the session lists", Scopes: []string{KeyScopeProjects, KeyScopeRecent}},
+	{Name: "reveal_hidden", Keys: []string{"ctrl+t"}, Help: "show or hide sessions hidden by the session policy", Scopes: []string{KeyScopeProjects, KeyScopeRecent}},
+	{Name: "next_focus", Keys: []string{"tab"}, Help: "focus the next column", Scopes: []string{KeyScopeProjects}},
+	{Name: "prev_focus", Keys: []string{"shift+tab"}, Help: "focus the previous column", Scopes: []string{KeyScopeProjects}},
+	{Name: "search_order", Keys: []string{"ctrl+o"}, Help: "toggle newest (phrase) / best match (all terms) order", Scopes: []string{KeyScopeSearch}},
+	{Name: "search_matches", Keys: []string{"tab"}, Help: "show the matches of the selected result", Scopes: []string{KeyScopeSearch}},
+	{Name: "next_match", Keys: []string{"ctrl+right"}, Help: "next match snippet", Scopes: []string{KeyScopeSearch}},
+	{Name: "prev_match", Keys: []string{"ctrl+left"}, Help: "previous match snippet", Scopes: []string{KeyScopeSearch}},
+	{Name: "save_search", Keys: []string{"ctrl+s"}, Help: "save the query, or pick a saved search when the search box is empty", Scopes: []string{KeyScopeSearch}},
+}
+
+func keyAction(name string) (KeyAction, bool) {
+	for _, a := range KeyActions {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return KeyAction{}, false
+}
+
+// KeyBindings returns the effective keys for every action: the defaults with
+// the keys: section applied. An empty list unbinds an action.
+func (c *Config) KeyBindings() map[string][]string {
+	out := make(map[string][]string, len(KeyActions))
+	for _, a := range KeyActions {
+		out[a.Name] = a.Keys
+		if c == nil {
+			continue
+		}
+		if keys, ok := c.Keys[a.Name]; ok {
+			out[a.Name] = normalizeKeys(keys)
+		}
+	}
+	return out
+}
+
+// normalizeKeys trims the key names but keeps their case: "G" and "g" are
+// different keys.
+func normalizeKeys(keys []string) []string {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func (c *Config) keyProblems() []problem {
+	var ps []problem
+	names := make([]string, 0, len(c.Keys))
+	for name := range c.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := "keys." + name
+		if _, ok := keyAction(name); !ok {
+			ps = append(ps, problem{path: path, msg: fmt.Sprintf("keys.%s is not a known action", name)})
+			continue
+		}
+		for i, k := range c.Keys[name] {
+			if strings.TrimSpace(k) == "" {
+				ps = append(ps, problem{path: fmt.Sprintf("%s[%d]", path, i), msg: fmt.Sprintf("keys.%s[%d] is empty", name, i)})
+			}
+		}
+	}
+
+	// A key bound to two actions in the same view only triggers one of them.
+	bindings := c.KeyBindings()
+	for _, scope := range []string{KeyScopeProjects, KeyScopeRecent, KeyScopeSearch} {
+		owner := map[string]string{}
+		for _, a := range KeyActions {
+			if !containsString(a.Scopes, scope) {
+				continue
+			}
+			for _, k := range bindings[a.Name] {
+				other, ok := owner[k]
+				if !ok {
+					owner[k] = a.Name
+					continue
+				}
+				path := "keys." + a.Name
+				if _, set := c.Keys[a.Name]; !set {
+					path = "keys." + other
+				}
+				ps = append(ps, problem{path: path, warning: true, msg: fmt.Sprintf("key %q is bound to both %s and %s in the %s view", k, other, a.Name, scope)})
+			}
+		}
+	}
+	return ps
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
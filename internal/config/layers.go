@@ -0,0 +1,420 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigName is the optional project-local config file, read from the
+// selected project's worktree.
+const ProjectConfigName = ".oc.yaml"
+
+// projectLayerKeys are the top-level keys a project-local config may set. Any
+// cloned repository can ship a .oc.yaml, so it is limited to picking models:
+// profiles (env, args) and includes (files outside the worktree) are rejected.
+var projectLayerKeys = map[string]bool{
+	"default_model": true,
+	"models":        true,
+	"model_order":   true,
+}
+
+// keyedSequences are merged item-by-item across layers, matching items on the
+// given field, instead of being replaced wholesale.
+var keyedSequences = map[string]string{
+	"models":   "name",
+	"profiles": "name",
+	"projects": "match",
+}
+
+// Layer is one config file in merge order; later layers override earlier ones.
+type Layer struct {
+	Path string
+	// Optional layers may be missing.
+	Optional bool
+}
+
+// LayerPaths returns the standard layers: system-wide configs from
+// $XDG_CONFIG_DIRS (default /etc/xdg), then the user config. userPath
+// overrides the user config location; when empty it is
+// $XDG_CONFIG_HOME/oc/oc-config.yaml (default ~/.config/oc/oc-config.yaml).
+func LayerPaths(userPath string) ([]Layer, error) {
+	dirs := filepath.SplitList(os.Getenv("XDG_CONFIG_DIRS"))
+	if len(dirs) == 0 {
+		dirs = []string{"/etc/xdg"}
+	}
+	layers := make([]Layer, 0, len(dirs)+1)
+	// XDG_CONFIG_DIRS is most-important-first; merge least important first.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if d := strings.TrimSpace(dirs[i]); d != "" {
+			layers = append(layers, Layer{Path: filepath.Join(d, "oc", "oc-config.yaml"), Optional: true})
+		}
+	}
+	if strings.TrimSpace(userPath) != "" {
+		return append(layers, Layer{Path: userPath}), nil
+	}
+	p, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, Layer{Path: p}), nil
+}
+
+// UserConfigPath returns $XDG_CONFIG_HOME/oc/oc-config.yaml, falling back to
+// ~/.config/oc/oc-config.yaml.
+func UserConfigPath() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); dir != "" {
+		return filepath.Join(dir, "oc", "oc-config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "oc", "oc-config.yaml"), nil
+}
+
+// LoadLayered reads and merges layers in order. Scalars and plain lists in a
+// later layer replace earlier values, mappings merge key by key, and models,
+// profiles and project rules merge by name (or match) so a layer can override
+// a single entry or add new ones. model_order reorders the merged models.
+func LoadLayered(layers []Layer) (*Config, error) {
+	var merged *yaml.Node
+	sources := map[string]string{}
+	loaded := 0
+	for _, l := range layers {
+		docs, err := readLayer(l.Path, false)
+		if err != nil {
+			if l.Optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		loaded++
+		for _, d := range docs {
+			merged = mergeLayer(merged, d.doc, d.path, sources)
+		}
+	}
+	if loaded == 0 {
+		return nil, fmt.Errorf("no config file found (looked in %s)", layerPathList(layers))
+	}
+	return decodeMerged(merged, sources)
+}
+
+// WithProject returns the config with the worktree's .oc.yaml applied on top,
+// or c itself when the project has none.
+func (c *Config) WithProject(worktree string) (*Config, error) {
+	if c == nil || c.merged == nil || strings.TrimSpace(worktree) == "" {
+		return c, nil
+	}
+	path := filepath.Join(worktree, ProjectConfigName)
+	docs, err := readLayer(path, true)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return c, err
+	}
+
+	sources := make(map[string]string, len(c.sources))
+	for k, v := range c.sources {
+		sources[k] = v
+	}
+	merged := cloneNode(c.merged)
+	for _, d := range docs {
+		merged = mergeLayer(merged, d.doc, d.path, sources)
+	}
+	pc, err := decodeMerged(merged, sources)
+	if err != nil {
+		return c, err
+	}
+	pc.discovered = c.discovered
+	pc.Models = mergeDiscovered(pc.Models, c.discovered)
+	return pc, nil
+}
+
+// Source returns the file that set the value at path (e.g. "default_model"),
+// or "" when it was not set by any layer.
+func (c *Config) Source(path string) string {
+	if c == nil {
+		return ""
+	}
+	return c.sources[path]
+}
+
+// AnnotatedYAML renders the effective merged config with a comment naming
+// the source file of each value.
+func (c *Config) AnnotatedYAML() ([]byte, error) {
+	if c == nil || c.merged == nil {
+		return nil, errors.New("no merged config available")
+	}
+	doc := cloneNode(c.merged)
+	annotate(docRoot(doc), "", c.sources)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readLayer reads path with its includes, failing on the first error.
+func readLayer(path string, project bool) ([]layerDoc, error) {
+	docs, err := expandLayer(path, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		for _, diag := range d.diags {
+			if diag.Severity == SeverityError {
+				return nil, diag.err()
+			}
+		}
+	}
+	return docs, nil
+}
+
+func decodeMerged(merged *yaml.Node, sources map[string]string) (*Config, error) {
+	var cfg Config
+	if err := merged.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	cfg.Models = visibleModels(cfg.Models)
+	if err := cfg.applyModelOrder(); err != nil {
+		return nil, err
+	}
+	cfg.merged = merged
+	cfg.sources = sources
+	return &cfg, nil
+}
+
+func (c *Config) applyModelOrder() error {
+	if len(c.ModelOrder) == 0 {
+		return nil
+	}
+	out := make([]Model, 0, len(c.Models))
+	used := make([]bool, len(c.Models))
+	for i, name := range c.ModelOrder {
+		found := false
+		for j, m := range c.Models {
+			if !used[j] && strings.EqualFold(m.Name, strings.TrimSpace(name)) {
+				out = append(out, m)
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("model_order[%d] %q does not match any configured model", i, name)
+		}
+	}
+	for j, m := range c.Models {
+		if !used[j] {
+			out = append(out, m)
+		}
+	}
+	c.Models = out
+	return nil
+}
+
+func layerPathList(layers []Layer) string {
+	paths := make([]string, 0, len(layers))
+	for _, l := range layers {
+		paths = append(paths, l.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+func docRoot(doc *yaml.Node) *yaml.Node {
+	if doc == nil {
+		return nil
+	}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mergeLayer merges doc into dst (which may be nil) and records sources.
+func mergeLayer(dst, doc *yaml.Node, file string, sources map[string]string) *yaml.Node {
+	src := docRoot(doc)
+	if dst == nil {
+		dst = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if src == nil || src.Kind != yaml.MappingNode {
+		return dst
+	}
+	mergeMapping(docRoot(dst), src, "", file, sources)
+	return dst
+}
+
+func mergeMapping(dst, src *yaml.Node, path, file string, sources map[string]string) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], src.Content[i+1]
+		childPath := joinPath(path, k.Value)
+		idx := mappingIndex(dst, k.Value)
+		if idx < 0 {
+			dst.Content = append(dst.Content, cloneNode(k), cloneNode(v))
+			recordSources(v, childPath, file, sources)
+			continue
+		}
+		cur := dst.Content[idx+1]
+		if field, ok := keyedSequences[childPath]; ok && cur.Kind == yaml.SequenceNode && v.Kind == yaml.SequenceNode {
+			mergeKeyedSequence(cur, v, childPath, field, file, sources)
+			continue
+		}
+		if cur.Kind == yaml.MappingNode && v.Kind == yaml.MappingNode {
+			mergeMapping(cur, v, childPath, file, sources)
+			continue
+		}
+		forgetSources(childPath, sources)
+		dst.Content[idx+1] = cloneNode(v)
+		recordSources(v, childPath, file, sources)
+	}
+}
+
+func mergeKeyedSequence(dst, src *yaml.Node, path, field, file string, sources map[string]string) {
+	for _, item := range src.Content {
+		key := itemKey(item, field)
+		if key != "" {
+			if match := findKeyedItem(dst, field, key); match != nil {
+				mergeMapping(match, item, itemPath(path, key), file, sources)
+				continue
+			}
+		}
+		dst.Content = append(dst.Content, cloneNode(item))
+		recordItemSources(item, path, field, len(dst.Content)-1, file, sources)
+	}
+}
+
+func recordSources(n *yaml.Node, path, file string, sources map[string]string) {
+	if field, ok := keyedSequences[path]; ok && n.Kind == yaml.SequenceNode {
+		for i, item := range n.Content {
+			recordItemSources(item, path, field, i, file, sources)
+		}
+		return
+	}
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			recordSources(n.Content[i+1], joinPath(path, n.Content[i].Value), file, sources)
+		}
+		return
+	}
+	sources[path] = file
+}
+
+func recordItemSources(item *yaml.Node, path, field string, idx int, file string, sources map[string]string) {
+	key := itemKey(item, field)
+	if key == "" {
+		key = fmt.Sprint(idx)
+	}
+	recordSources(item, itemPath(path, key), file, sources)
+}
+
+func forgetSources(path string, sources map[string]string) {
+	for k := range sources {
+		if k == path || strings.HasPrefix(k, path+".") || strings.HasPrefix(k, path+"[") {
+			delete(sources, k)
+		}
+	}
+}
+
+// annotate sets a line comment naming the source on every value node.
+func annotate(n *yaml.Node, path string, sources map[string]string) {
+	if n == nil {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			childPath := joinPath(path, k.Value)
+			if src, ok := sources[childPath]; ok {
+				k.LineComment = src
+			}
+			annotate(v, childPath, sources)
+		}
+	case yaml.SequenceNode:
+		field, ok := keyedSequences[path]
+		if !ok {
+			return
+		}
+		for i, item := range n.Content {
+			key := itemKey(item, field)
+			if key == "" {
+				key = fmt.Sprint(i)
+			}
+			annotate(item, itemPath(path, key), sources)
+		}
+	}
+}
+
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func itemKey(item *yaml.Node, field string) string {
+	if item.Kind != yaml.MappingNode {
+		return ""
+	}
+	if i := mappingIndex(item, field); i >= 0 {
+		return strings.TrimSpace(item.Content[i+1].Value)
+	}
+	return ""
+}
+
+func findKeyedItem(seq *yaml.Node, field, key string) *yaml.Node {
+	for _, it := range seq.Content {
+		if strings.EqualFold(itemKey(it, field), key) {
+			return it
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func itemPath(path, key string) string {
+	return path + "[" + key + "]"
+}
+
+func cloneNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	if len(n.Content) > 0 {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, child := range n.Content {
+			c.Content[i] = cloneNode(child)
+		}
+	}
+	if n.Alias != nil {
+		c.Alias = cloneNode(n.Alias)
+	}
+	return &c
+}
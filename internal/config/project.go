@@ -0,0 +1,188 @@
+package config
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectModels is the model selection that applies to a single project.
+type ProjectModels struct {
+	// Matched reports whether any project rule with model settings applied.
+	Matched bool
+	Models  []Model
+	Default Model
+	// Profiles are the profiles whose model is available to the project, with
+	// Resolved set.
+	Profiles []Profile
+}
+
+// ForProject resolves the models and default model for a project worktree.
+//
+// The project's .oc.yaml, if any, is layered on top first. Rules are then
+// evaluated in order; for each setting, the first matching rule that sets it
+// wins. Without a matching rule the global list and default apply. When
+// discovery is enabled, models from the project's own OpenCode config are
+// appended as well.
+//
+// On a broken .oc.yaml the error is returned together with the result for the
+// config without it, so callers can report it and carry on.
+func (c *Config) ForProject(worktree string) (ProjectModels, error) {
+	base, err := c.WithProject(worktree)
+	pc := *base
+	pc.Models = base.projectDiscoveredModels(worktree)
+	return pc.forProject(worktree), err
+}
+
+func (c *Config) forProject(worktree string) ProjectModels {
+	out := c.forProjectModels(worktree)
+	for _, p := range c.Profiles {
+		m, ok := c.findModel(p.Model)
+		if !ok || !containsModel(out.Models, m) {
+			continue
+		}
+		p.Resolved = m
+		out.Profiles = append(out.Profiles, p)
+	}
+	return out
+}
+
+func (c *Config) forProjectModels(worktree string) ProjectModels {
+	out := ProjectModels{Models: c.Models}
+	if def, err := c.Default(); err == nil {
+		out.Default = def
+	}
+
+	var defaultRef string
+	var subset []string
+	for _, r := range c.Projects {
+		if !r.Matches(worktree) || (len(r.Models) == 0 && strings.TrimSpace(r.DefaultModel) == "") {
+			continue
+		}
+		out.Matched = true
+		if subset == nil && len(r.Models) > 0 {
+			subset = r.Models
+		}
+		if defaultRef == "" && strings.TrimSpace(r.DefaultModel) != "" {
+			defaultRef = r.DefaultModel
+		}
+	}
+	if !out.Matched {
+		return out
+	}
+
+	if subset != nil {
+		if allowed := c.resolveModels(subset); len(allowed) > 0 {
+			out.Models = allowed
+		}
+	}
+	if defaultRef != "" {
+		if def, ok := c.findModel(defaultRef); ok && containsModel(out.Models, def) {
+			out.Default = def
+			return out
+		}
+	}
+	if !containsModel(out.Models, out.Default) && len(out.Models) > 0 {
+		out.Default = out.Models[0]
+	}
+	return out
+}
+
+// ProjectDisplay is how a project is shown in the project list.
+type ProjectDisplay struct {
+	Name    string
+	Aliases []string
+	Icon    string
+	Tag     string
+	Hide    bool
+}
+
+// ProjectDisplay resolves the display settings for a project worktree. As
+// with model settings, the first matching rule that sets a field wins.
+func (c *Config) ProjectDisplay(worktree string) ProjectDisplay {
+	var out ProjectDisplay
+	if c == nil {
+		return out
+	}
+	hideSet := false
+	for _, r := range c.Projects {
+		if !r.Matches(worktree) {
+			continue
+		}
+		if out.Name == "" {
+			out.Name = strings.TrimSpace(r.Name)
+		}
+		if out.Aliases == nil && len(r.Aliases) > 0 {
+			out.Aliases = r.Aliases
+		}
+		if out.Icon == "" {
+			out.Icon = strings.TrimSpace(r.Icon)
+		}
+		if out.Tag == "" {
+			out.Tag = strings.TrimSpace(r.Tag)
+		}
+		if !hideSet && r.Hide != nil {
+			out.Hide, hideSet = *r.Hide, true
+		}
+	}
+	return out
+}
+
+// Matches reports whether the rule applies to worktree.
+func (r ProjectRule) Matches(worktree string) bool {
+	pattern := expandHome(strings.TrimSpace(r.Match))
+	worktree = strings.TrimSpace(worktree)
+	if pattern == "" || worktree == "" {
+		return false
+	}
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	worktree = filepath.ToSlash(filepath.Clean(worktree))
+	if strings.ContainsAny(pattern, "*?[") {
+		return globMatch(pattern, worktree)
+	}
+	if worktree == pattern {
+		return true
+	}
+	return strings.HasPrefix(worktree, strings.TrimSuffix(pattern, "/")+"/")
+}
+
+// globMatch matches slash-separated paths where "**" spans any number of
+// segments (including none) and other segments use path.Match syntax.
+func globMatch(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			rest := pat[1:]
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(rest, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		ok, err := path.Match(pat[0], segs[0])
+		if err != nil || !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
@@ -0,0 +1,12 @@
+package config
+
+import _ "embed"
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// JSONSchema returns the JSON Schema for oc-config.yaml, for editor
+// completion and validation.
+func JSONSchema() []byte {
+	return append([]byte(nil), schemaJSON...)
+}
@@ -0,0 +1,21 @@
+package config
+
+// DefaultSearchHistory is how many recent search queries are kept when
+// ui.search_history is not set.
+const DefaultSearchHistory = 100
+
+// SearchHistoryLimit is how many recent search queries oc keeps; 0 keeps
+// none.
+func (u UI) SearchHistoryLimit() int {
+	if u.SearchHistory == nil {
+		return DefaultSearchHistory
+	}
+	return *u.SearchHistory
+}
+
+func (c *Config) searchProblems() []problem {
+	if c.UI.SearchHistory != nil && *c.UI.SearchHistory < 0 {
+		return []problem{{path: "ui.search_history", msg: "ui.search_history must be >= 0"}}
+	}
+	return nil
+}
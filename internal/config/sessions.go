@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Groupings for ui.recent_group.
+const (
+	RecentGroupDate    = "date"    // Today, Yesterday, ... (default)
+	RecentGroupProject = "project" // one group per project
+)
+
+// RecentByProject reports whether the recent sessions view groups by project.
+func (u UI) RecentByProject() bool { return strings.TrimSpace(u.RecentGroup) == RecentGroupProject }
+
+// SessionPolicy hides sessions from the session lists. Unset fields inherit
+// from the next policy in line; an explicit 0 means no limit.
+type SessionPolicy struct {
+	// MaxAgeDays hides sessions not updated in the last N days.
+	MaxAgeDays *int `yaml:"max_age_days"`
+	// MaxCount keeps only the N most recently updated sessions.
+	MaxCount *int `yaml:"max_count"`
+	// MinMessages hides sessions with fewer messages, e.g. 1 hides empty ones.
+	MinMessages *int `yaml:"min_messages"`
+}
+
+// SessionLimits is a resolved SessionPolicy; 0 means no limit.
+type SessionLimits struct {
+	MaxAgeDays  int
+	MaxCount    int
+	MinMessages int
+	// MaxAgeSet reports that a policy set max_age_days, possibly to 0, so
+	// ui.global_sessions_max_age_days must not fill it in.
+	MaxAgeSet bool
+}
+
+// IsZero reports whether no limit is set.
+func (l SessionLimits) IsZero() bool {
+	return l.MaxAgeDays == 0 && l.MaxCount == 0 && l.MinMessages == 0
+}
+
+// fill sets every limit still unset in l from p.
+func (p SessionPolicy) fill(l *SessionLimits, set *[3]bool) {
+	for i, v := range []*int{p.MaxAgeDays, p.MaxCount, p.MinMessages} {
+		if v == nil || set[i] {
+			continue
+		}
+		set[i] = true
+		switch i {
+		case 0:
+			l.MaxAgeDays = *v
+			l.MaxAgeSet = true
+		case 1:
+			l.MaxCount = *v
+		case 2:
+			l.MinMessages = *v
+		}
+	}
+}
+
+// ProjectSessionLimits resolves the session policy for a project: each field
+// comes from the first matching project rule that sets it, then ui.sessions.
+func (c *Config) ProjectSessionLimits(worktree string) SessionLimits {
+	var out SessionLimits
+	if c == nil {
+		return out
+	}
+	var set [3]bool
+	for _, r := range c.Projects {
+		if r.Sessions != nil && r.Matches(worktree) {
+			r.Sessions.fill(&out, &set)
+		}
+	}
+	c.UI.Sessions.fill(&out, &set)
+	return out
+}
+
+// RecentSessionLimits resolves the policy for the recent sessions view:
+// ui.recent_sessions, then ui.sessions.
+func (c *Config) RecentSessionLimits() SessionLimits {
+	var out SessionLimits
+	if c == nil {
+		return out
+	}
+	var set [3]bool
+	c.UI.RecentSessions.fill(&out, &set)
+	c.UI.Sessions.fill(&out, &set)
+	return out
+}
+
+// CountsMessages reports whether any session policy sets min_messages, the
+// only limit that needs each session's message count.
+func (c *Config) CountsMessages() bool {
+	if c == nil {
+		return false
+	}
+	policies := []SessionPolicy{c.UI.Sessions, c.UI.RecentSessions}
+	for _, r := range c.Projects {
+		if r.Sessions != nil {
+			policies = append(policies, *r.Sessions)
+		}
+	}
+	for _, p := range policies {
+		if p.MinMessages != nil && *p.MinMessages > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func sessionPolicyProblems(path string, p SessionPolicy) []problem {
+	var ps []problem
+	for _, f := range []struct {
+		name string
+		v    *int
+	}{{"max_age_days", p.MaxAgeDays}, {"max_count", p.MaxCount}, {"min_messages", p.MinMessages}} {
+		if f.v != nil && *f.v < 0 {
+			ps = append(ps, problem{path: path + "." + f.name, msg: fmt.Sprintf("%s.%s must be >= 0", path, f.name)})
+		}
+	}
+	return ps
+}
+
+func (c *Config) sessionProblems() []problem {
+	ps := sessionPolicyProblems("ui.sessions", c.UI.Sessions)
+	ps = append(ps, sessionPolicyProblems("ui.recent_sessions", c.UI.RecentSessions)...)
+	if v := strings.TrimSpace(c.UI.RecentGroup); v != "" && v != RecentGroupDate && v != RecentGroupProject {
+		ps = append(ps, problem{path: "ui.recent_group", msg: fmt.Sprintf("ui.recent_group %q is not one of %s, %s", c.UI.RecentGroup, RecentGroupDate, RecentGroupProject)})
+	}
+	for i, r := range c.Projects {
+		if r.Sessions != nil {
+			ps = append(ps, sessionPolicyProblems(fmt.Sprintf("projects[%d].sessions", i), *r.Sessions)...)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sort modes for ui.sort.
+const (
+	SortRecent   = "recent"   // projects: last updated in OpenCode first
+	SortConfig   = "config"   // models: the order in the config
+	SortFrecency = "frecency" // most often and most recently launched first
+)
+
+// Sort picks the initial order of the project and model columns; both can
+// be toggled in the TUI.
+type Sort struct {
+	// Projects is "recent" (default) or "frecency".
+	Projects string `yaml:"projects"`
+	// Models is "config" (default) or "frecency".
+	Models string `yaml:"models"`
+}
+
+// FrecentProjects reports whether projects start sorted by frecency.
+func (s Sort) FrecentProjects() bool { return strings.TrimSpace(s.Projects) == SortFrecency }
+
+// FrecentModels reports whether models start sorted by frecency.
+func (s Sort) FrecentModels() bool { return strings.TrimSpace(s.Models) == SortFrecency }
+
+func (c *Config) sortProblems() []problem {
+	var ps []problem
+	for _, f := range []struct {
+		name  string
+		v     string
+		modes []string
+	}{
+		{"projects", c.UI.Sort.Projects, []string{SortRecent, SortFrecency}},
+		{"models", c.UI.Sort.Models, []string{SortConfig, SortFrecency}},
+	} {
+		if v := strings.TrimSpace(f.v); v != "" && !containsString(f.modes, v) {
+			path := "ui.sort." + f.name
+			ps = append(ps, problem{path: path, msg: fmt.Sprintf("%s %q is not one of %s", path, f.v, strings.Join(f.modes, ", "))})
+		}
+	}
+	return ps
+}
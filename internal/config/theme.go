@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Theme selects a built-in color theme and overrides individual style roles.
+type Theme struct {
+	// Name is one of ThemeNames; empty means "auto".
+	Name string `yaml:"name"`
+	// Roles maps a role (see ThemeRoles) to a color: an ANSI color number
+	// (0-255) or a hex color (#RGB or #RRGGBB).
+	Roles map[string]string `yaml:"roles"`
+}
+
+// ThemeNames are the built-in themes. "auto" picks dark or light from the
+// terminal background.
+var ThemeNames = []string{"auto", "dark", "light", "high-contrast"}
+
+// ThemeRoles are the style roles a theme colors.
+var ThemeRoles = []string{
+	"accent",               // focused titles and borders
+	"title",                // unfocused titles
+	"muted",                // hints, status and secondary text
+	"border",               // unfocused borders
+	"key",                  // keys in help lines
+	"text",                 // list item titles
+	"description",          // list item descriptions
+	"selected",             // selected list item title and marker
+	"selected_description", // selected list item description
+}
+
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ValidColor reports whether s is an ANSI color number or a hex color.
+func ValidColor(s string) bool {
+	s = strings.TrimSpace(s)
+	if hexColorRe.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+func (c *Config) themeProblems() []problem {
+	var ps []problem
+	t := c.UI.Theme
+	if name := strings.TrimSpace(t.Name); name != "" && !containsString(ThemeNames, name) {
+		ps = append(ps, problem{path: "ui.theme.name", msg: fmt.Sprintf("ui.theme.name %q is not one of %s", t.Name, strings.Join(ThemeNames, ", "))})
+	}
+	roles := make([]string, 0, len(t.Roles))
+	for r := range t.Roles {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	for _, r := range roles {
+		path := "ui.theme.roles." + r
+		if !containsString(ThemeRoles, r) {
+			ps = append(ps, problem{path: path, msg: fmt.Sprintf("%s is not a known role", path)})
+			continue
+		}
+		if !ValidColor(t.Roles[r]) {
+			ps = append(ps, problem{path: path, msg: fmt.Sprintf("%s %q is not a color (use 0-255 or #RRGGBB)", path, t.Roles[r])})
+		}
+	}
+	return ps
+}
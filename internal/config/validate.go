@@ -0,0 +1,386 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity ranks a Diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic is a single problem found by Validate. Line and Column are
+// 1-based; they are 0 when the problem has no position in the file.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.position(), d.Severity, d.Message)
+}
+
+func (d Diagnostic) position() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			pos += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	return pos
+}
+
+func (d Diagnostic) err() error {
+	return errors.New(d.position() + ": " + d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks every layer, plus project's .oc.yaml when project is not
+// empty, and reports all problems at once instead of stopping at the first.
+// Each file is checked on its own for syntax, unknown fields and value types;
+// the merged result is then checked like Load does.
+func Validate(layers []Layer, project string) []Diagnostic {
+	projectPath := ""
+	if strings.TrimSpace(project) != "" {
+		projectPath = filepath.Join(project, ProjectConfigName)
+		layers = append(append([]Layer(nil), layers...), Layer{Path: projectPath, Optional: true})
+	}
+
+	var diags []Diagnostic
+	var order []string
+	var merged *yaml.Node
+	sources := map[string]string{}
+	loaded := 0
+	for _, l := range layers {
+		docs, err := expandLayer(l.Path, l.Path == projectPath)
+		if err != nil {
+			if l.Optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			order = append(order, l.Path)
+			diags = append(diags, Diagnostic{File: l.Path, Message: err.Error()})
+			continue
+		}
+		for _, d := range docs {
+			order = append(order, d.path)
+			diags = append(diags, d.diags...)
+			if d.doc == nil {
+				continue
+			}
+			loaded++
+			merged = mergeLayer(merged, d.doc, d.path, sources)
+		}
+	}
+	if loaded == 0 {
+		if len(diags) == 0 && len(layers) > 0 {
+			diags = append(diags, Diagnostic{File: layers[len(layers)-1].Path, Message: "no config file found (looked in " + layerPathList(layers) + ")"})
+		}
+		return diags
+	}
+
+	// Structural problems are already reported per file; decode what we can.
+	var cfg Config
+	if err := merged.Decode(&cfg); err != nil {
+		var te *yaml.TypeError
+		if !errors.As(err, &te) {
+			sortDiagnostics(diags, order)
+			return diags
+		}
+	}
+	for _, p := range cfg.problems() {
+		d := locate(merged, p.path, sources, layers)
+		d.Message = p.msg
+		if p.warning {
+			d.Severity = SeverityWarning
+		}
+		diags = append(diags, d)
+	}
+	sortDiagnostics(diags, order)
+	return diags
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+)`)
+
+// parseLayer parses one file, interpolates environment variables and checks it
+// against the Config structure. It returns nil when the file cannot be parsed
+// at all.
+func parseLayer(path string, b []byte, project bool) (*yaml.Node, []Diagnostic) {
+	var diags []Diagnostic
+	report := func(n *yaml.Node, sev Severity, format string, args ...any) {
+		d := Diagnostic{File: path, Severity: sev, Message: fmt.Sprintf(format, args...)}
+		if n != nil {
+			d.Line, d.Column = n.Line, n.Column
+		}
+		diags = append(diags, d)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		d := Diagnostic{File: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Message = strings.TrimPrefix(d.Message, m[0]+": ")
+		}
+		return nil, append(diags, d)
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); err == nil {
+		report(&extra, SeverityError, "multiple documents are not supported")
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	root := docRoot(&doc)
+	if root == nil {
+		return &doc, diags
+	}
+	interpolate(root, report)
+	checkNode(root, reflect.TypeOf(Config{}), "", report)
+	if project && root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if k := root.Content[i]; !projectLayerKeys[k.Value] {
+				report(k, SeverityError, "%q is not supported in %s", k.Value, ProjectConfigName)
+			}
+		}
+	}
+	if root.Kind == yaml.MappingNode {
+		if i := mappingIndex(root, "models"); i >= 0 {
+			checkDuplicateNames(root.Content[i+1], report)
+		}
+	}
+	return &doc, diags
+}
+
+// checkNode reports unknown fields and values that do not fit the Go type.
+func checkNode(n *yaml.Node, t reflect.Type, path string, report func(*yaml.Node, Severity, string, ...any)) {
+	if n == nil {
+		return
+	}
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	at := func(msg string) string {
+		if path == "" {
+			return msg
+		}
+		return path + ": " + msg
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			report(n, SeverityError, "%s", at("expected a mapping"))
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if seen[k.Value] {
+				report(k, SeverityError, "%s", at(fmt.Sprintf("key %q is defined more than once", k.Value)))
+				continue
+			}
+			seen[k.Value] = true
+			f, ok := yamlField(t, k.Value)
+			if !ok {
+				report(k, SeverityError, "%s", at(fmt.Sprintf("unknown field %q", k.Value)))
+				continue
+			}
+			checkNode(v, f.Type, joinPath(path, k.Value), report)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			report(n, SeverityError, "%s", at("expected a list"))
+			return
+		}
+		for i, item := range n.Content {
+			checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			report(n, SeverityError, "%s", at("expected a mapping"))
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			checkNode(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value), report)
+		}
+	default:
+		if n.Kind != yaml.ScalarNode {
+			report(n, SeverityError, "%s", at("expected a "+kindName(t)))
+			return
+		}
+		if err := n.Decode(reflect.New(t).Interface()); err != nil {
+			report(n, SeverityError, "%s", at(fmt.Sprintf("cannot use %q as a %s", n.Value, kindName(t))))
+		}
+	}
+}
+
+// yamlField finds the struct field decoded from key.
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name == key && name != "-" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return "number"
+	}
+	return t.Kind().String()
+}
+
+// checkDuplicateNames warns about models listed twice in one file; later
+// layers would silently merge them into one entry.
+func checkDuplicateNames(models *yaml.Node, report func(*yaml.Node, Severity, string, ...any)) {
+	if models.Kind != yaml.SequenceNode {
+		return
+	}
+	seen := map[string]int{}
+	for i, item := range models.Content {
+		name := strings.ToLower(itemKey(item, "name"))
+		if name == "" {
+			continue
+		}
+		if j, ok := seen[name]; ok {
+			n := item.Content[mappingIndex(item, "name")+1]
+			report(n, SeverityWarning, "models[%d].name %q duplicates models[%d]", i, n.Value, j)
+			continue
+		}
+		seen[name] = i
+	}
+}
+
+var pathSegmentRe = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// locate positions a problem path (e.g. "models[2].model") in the merged
+// YAML. Missing nodes resolve to their nearest existing parent.
+func locate(merged *yaml.Node, path string, sources map[string]string, layers []Layer) Diagnostic {
+	n := docRoot(merged)
+	keyed := ""
+	for _, seg := range pathSegmentRe.FindAllString(path, -1) {
+		if strings.HasPrefix(seg, "[") {
+			i, _ := strconv.Atoi(strings.Trim(seg, "[]"))
+			if n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+				break
+			}
+			key := ""
+			if field, ok := keyedSequences[keyed]; ok {
+				key = itemKey(n.Content[i], field)
+			}
+			if key == "" {
+				key = strconv.Itoa(i)
+			}
+			keyed = itemPath(keyed, key)
+			n = n.Content[i]
+			continue
+		}
+		if n.Kind != yaml.MappingNode {
+			break
+		}
+		i := mappingIndex(n, seg)
+		if i < 0 {
+			break
+		}
+		keyed = joinPath(keyed, seg)
+		n = n.Content[i+1]
+	}
+	if keyed == "" {
+		// Nothing to point at; blame the most specific layer.
+		return Diagnostic{File: layers[len(layers)-1].Path}
+	}
+	return Diagnostic{File: sourceOf(n, keyed, sources), Line: n.Line, Column: n.Column}
+}
+
+// sourceOf returns the file n came from. Containers take the file of their
+// first entry, which is the layer that introduced them.
+func sourceOf(n *yaml.Node, keyed string, sources map[string]string) string {
+	if f, ok := sources[keyed]; ok {
+		return f
+	}
+	switch {
+	case n.Kind == yaml.MappingNode && len(n.Content) >= 2:
+		return sourceOf(n.Content[1], joinPath(keyed, n.Content[0].Value), sources)
+	case n.Kind == yaml.SequenceNode && len(n.Content) > 0:
+		key := ""
+		if field, ok := keyedSequences[keyed]; ok {
+			key = itemKey(n.Content[0], field)
+		}
+		if key == "" {
+			key = "0"
+		}
+		return sourceOf(n.Content[0], itemPath(keyed, key), sources)
+	}
+	return ""
+}
+
+// sortDiagnostics orders diagnostics by file, in merge order, then position.
+func sortDiagnostics(diags []Diagnostic, files []string) {
+	order := make(map[string]int, len(files))
+	for i, f := range files {
+		if _, ok := order[f]; !ok {
+			order[f] = i
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if order[a.File] != order[b.File] {
+			return order[a.File] < order[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
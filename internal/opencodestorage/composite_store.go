@@ -14,35 +14,45 @@ import (
 type CompositeStore struct {
 	json   Store
 	sqlite Store
+	// sqliteErr is why the SQLite source could not be opened; the store then
+	// reads JSON only.
+	sqliteErr error
+	dbPath    string
 
 	mu      sync.RWMutex
 	aliases map[string]projectAlias
 }
 
 func (s *CompositeStore) RecentSessions(ctx context.Context, limit int) ([]SessionSearchResult, error) {
+	res, _, err := s.RecentSessionsBefore(ctx, RecentCursor{}, limit)
+	return res, err
+}
+
+func (s *CompositeStore) RecentSessionsBefore(ctx context.Context, cursor RecentCursor, limit int) ([]SessionSearchResult, bool, error) {
 	haveSQLite := s.sqlite != nil
 	haveJSON := s.json != nil
 	if !haveSQLite && !haveJSON {
-		return nil, fmt.Errorf("no storage sources configured")
+		return nil, false, fmt.Errorf("no storage sources configured")
 	}
 
 	var dbRes []SessionSearchResult
 	var jsonRes []SessionSearchResult
+	var dbMore, jsonMore bool
 	var dbErr error
 	var jsonErr error
 
 	if haveSQLite {
-		dbRes, dbErr = s.sqlite.RecentSessions(ctx, limit)
+		dbRes, dbMore, dbErr = s.sqlite.RecentSessionsBefore(ctx, cursor, limit)
 	}
 	if haveJSON {
-		jsonRes, jsonErr = s.json.RecentSessions(ctx, limit)
+		jsonRes, jsonMore, jsonErr = s.json.RecentSessionsBefore(ctx, cursor, limit)
 	}
 
 	if haveSQLite && dbErr == nil && (!haveJSON || jsonErr != nil) {
-		return dbRes, nil
+		return dbRes, dbMore, nil
 	}
 	if haveJSON && jsonErr == nil && (!haveSQLite || dbErr != nil) {
-		return jsonRes, nil
+		return jsonRes, jsonMore, nil
 	}
 	if haveSQLite && haveJSON && dbErr == nil && jsonErr == nil {
 		// Prefer SQLite on duplicates.
@@ -67,22 +77,26 @@ func (s *CompositeStore) RecentSessions(ctx context.Context, limit int) ([]Sessi
 			seen[k] = struct{}{}
 			out = append(out, r)
 		}
-		sort.SliceStable(out, func(i, j int) bool { return out[i].Session.Updated > out[j].Session.Updated })
+		sort.SliceStable(out, func(i, j int) bool { return newerFirst(out[i].Session, out[j].Session) })
+		// Dropped duplicates can leave the page short while either source
+		// still has older sessions, so ask the sources.
+		more := dbMore || jsonMore
 		if limit > 0 && len(out) > limit {
 			out = out[:limit]
+			more = true
 		}
-		return out, nil
+		return out, more, nil
 	}
 	if haveSQLite && haveJSON {
-		return nil, fmt.Errorf("sqlite: %v; json: %v", dbErr, jsonErr)
+		return nil, false, fmt.Errorf("sqlite: %v; json: %v", dbErr, jsonErr)
 	}
 	if haveSQLite {
-		return nil, dbErr
+		return nil, false, dbErr
 	}
-	return nil, jsonErr
+	return nil, false, jsonErr
 }
 
-func (s *CompositeStore) SearchSessions(ctx context.Context, query string, limit int) ([]SessionSearchResult, error) {
+func (s *CompositeStore) SearchSessions(ctx context.Context, query string, limit int, order SearchOrder) ([]SessionSearchResult, error) {
 	haveSQLite := s.sqlite != nil
 	haveJSON := s.json != nil
 	if !haveSQLite && !haveJSON {
@@ -90,20 +104,20 @@ func (s *CompositeStore) SearchSessions(ctx context.Context, query string, limit
 	}
 	// Prefer SQLite: it's the only source that can contain transcript text.
 	if haveSQLite {
-		return s.sqlite.SearchSessions(ctx, query, limit)
+		return s.sqlite.SearchSessions(ctx, query, limit, order)
 	}
-	return s.json.SearchSessions(ctx, query, limit)
+	return s.json.SearchSessions(ctx, query, limit, order)
 }
 
-func (s *CompositeStore) SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int) ([]SessionSearchResult, error) {
+func (s *CompositeStore) SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int, order SearchOrder) ([]SessionSearchResult, error) {
 	if s.sqlite != nil {
 		if w, ok := s.sqlite.(WindowSearchStore); ok {
-			return w.SearchSessionsWindow(ctx, query, limit, candidateLimit)
+			return w.SearchSessionsWindow(ctx, query, limit, candidateLimit, order)
 		}
-		return s.sqlite.SearchSessions(ctx, query, limit)
+		return s.sqlite.SearchSessions(ctx, query, limit, order)
 	}
 	if s.json != nil {
-		return s.json.SearchSessions(ctx, query, limit)
+		return s.json.SearchSessions(ctx, query, limit, order)
 	}
 	return nil, fmt.Errorf("no storage sources configured")
 }
@@ -118,6 +132,9 @@ type OpenOptions struct {
 	DBPath        string
 	UseLegacy     bool
 	DisableSQLite bool
+	// CountMessages makes the stores fill in Session.Messages, which costs a
+	// query or a directory read per session; set it only when needed.
+	CountMessages bool
 }
 
 // OpenStore opens the appropriate store for the configured data sources.
@@ -133,17 +150,38 @@ func OpenStore(opts OpenOptions) (Store, error) {
 		if err != nil {
 			return nil, err
 		}
+		st.CountMessages = opts.CountMessages
 		return st, nil
 	}
 
-	jsonStore := Store(NewJSONStore(opts.StorageRoot))
-	var sqliteStore Store
+	js := NewJSONStore(opts.StorageRoot)
+	js.CountMessages = opts.CountMessages
+	cs := &CompositeStore{json: js, dbPath: opts.DBPath, aliases: map[string]projectAlias{}}
 	if !opts.DisableSQLite {
 		if s, err := OpenSQLiteStore(opts.DBPath); err == nil {
-			sqliteStore = s
+			s.CountMessages = opts.CountMessages
+			cs.sqlite = s
+		} else {
+			cs.sqliteErr = err
 		}
 	}
-	return &CompositeStore{json: jsonStore, sqlite: sqliteStore, aliases: map[string]projectAlias{}}, nil
+	return cs, nil
+}
+
+// Sources reports SQLite, when enabled, then JSON. A SQLite database that
+// could not be opened is listed with its open error.
+func (s *CompositeStore) Sources() []SourceStatus {
+	var out []SourceStatus
+	switch {
+	case s.sqlite != nil:
+		out = append(out, SourcesOf(s.sqlite)...)
+	case s.sqliteErr != nil:
+		out = append(out, SourceStatus{Name: SourceSQLite, Path: s.dbPath, Err: s.sqliteErr})
+	}
+	if s.json != nil {
+		out = append(out, SourcesOf(s.json)...)
+	}
+	return out
 }
 
 func (s *CompositeStore) Projects(ctx context.Context) ([]Project, error) {
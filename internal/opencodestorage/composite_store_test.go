@@ -80,6 +80,13 @@ func TestCompositeStore_MergesAndPrefersSQLiteOnDuplicateIDs(t *testing.T) {
 	if sessions[0].ID != "s1" || sessions[0].Title != "from-db" {
 		t.Fatalf("expected sqlite session to win: %+v", sessions[0])
 	}
+
+	// Each source counts what it read, before the merge.
+	for _, src := range SourcesOf(st) {
+		if src.Projects != 1 || src.Sessions != 1 || src.Err != nil {
+			t.Fatalf("expected 1 project and 1 session from %s, got %+v", src.Name, src)
+		}
+	}
 }
 
 func TestCompositeStore_GlobalCollisionProducesCanonicalAndAliasProjects(t *testing.T) {
@@ -208,6 +215,14 @@ func TestCompositeStore_FallsBackToJSONWhenSQLiteCannotOpen(t *testing.T) {
 	if len(projects) != 1 || projects[0].Worktree != "/json" {
 		t.Fatalf("expected json-only projects, got %+v", projects)
 	}
+
+	sources := SourcesOf(st)
+	if len(sources) != 2 || sources[0].Name != SourceSQLite || sources[0].Err == nil {
+		t.Fatalf("expected the sqlite open failure to be reported, got %+v", sources)
+	}
+	if sources[1].Name != SourceJSON || sources[1].Path != root || sources[1].Projects != 1 || sources[1].Err != nil {
+		t.Fatalf("expected a healthy json source with 1 project, got %+v", sources[1])
+	}
 }
 
 func TestCheckStorageReadable_OKIfDBReadableEvenWhenJSONMissing(t *testing.T) {
@@ -10,6 +10,11 @@ import (
 
 type JSONStore struct {
 	StorageRoot string
+	// CountMessages fills in Session.Messages; it reads a directory per
+	// session, so it is off unless a session policy needs the counts.
+	CountMessages bool
+
+	stats sourceStats
 }
 
 func NewJSONStore(storageRoot string) *JSONStore {
@@ -18,20 +23,32 @@ func NewJSONStore(storageRoot string) *JSONStore {
 
 func (s *JSONStore) Projects(ctx context.Context) ([]Project, error) {
 	_ = ctx
-	return LoadProjects(s.StorageRoot)
+	projects, err := LoadProjects(s.StorageRoot)
+	s.stats.recordProjects(len(projects), err)
+	return projects, err
 }
 
 func (s *JSONStore) Sessions(ctx context.Context, projectID string) ([]Session, error) {
 	_ = ctx
-	return LoadSessions(s.StorageRoot, projectID)
+	sessions, err := LoadSessions(s.StorageRoot, projectID)
+	if err == nil && s.CountMessages {
+		CountMessages(s.StorageRoot, sessions)
+	}
+	s.stats.recordSessions(projectID, len(sessions), err)
+	return sessions, err
+}
+
+// Sources reports the storage directory and what was read from it.
+func (s *JSONStore) Sources() []SourceStatus {
+	return []SourceStatus{s.stats.status(SourceJSON, s.StorageRoot)}
 }
 
 type recentSessionsMinHeap []SessionSearchResult
 
 func (h recentSessionsMinHeap) Len() int { return len(h) }
 func (h recentSessionsMinHeap) Less(i, j int) bool {
-	// Min-heap by Updated.
-	return h[i].Session.Updated < h[j].Session.Updated
+	// Min-heap: the oldest session on top.
+	return newerFirst(h[j].Session, h[i].Session)
 }
 func (h recentSessionsMinHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
 func (h *recentSessionsMinHeap) Push(x any)   { *h = append(*h, x.(SessionSearchResult)) }
@@ -44,17 +61,24 @@ func (h *recentSessionsMinHeap) Pop() any {
 }
 
 func (s *JSONStore) RecentSessions(ctx context.Context, limit int) ([]SessionSearchResult, error) {
+	res, _, err := s.RecentSessionsBefore(ctx, RecentCursor{}, limit)
+	return res, err
+}
+
+func (s *JSONStore) RecentSessionsBefore(ctx context.Context, cursor RecentCursor, limit int) ([]SessionSearchResult, bool, error) {
 	_ = ctx
 	if limit <= 0 {
-		return []SessionSearchResult{}, nil
+		return []SessionSearchResult{}, false, nil
 	}
 
 	projects, err := LoadProjects(s.StorageRoot)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	h := make(recentSessionsMinHeap, 0, minInt(limit, 64))
+	// Keep one extra session to tell whether another page follows.
+	keep := limit + 1
+	h := make(recentSessionsMinHeap, 0, minInt(keep, 64))
 	heap.Init(&h)
 
 	for _, p := range projects {
@@ -65,18 +89,18 @@ func (s *JSONStore) RecentSessions(ctx context.Context, limit int) ([]SessionSea
 		}
 		sessions, err := LoadSessions(s.StorageRoot, pid)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		for _, ses := range sessions {
-			if strings.TrimSpace(ses.ID) == "" {
+			if strings.TrimSpace(ses.ID) == "" || !cursor.admits(ses) {
 				continue
 			}
 			r := SessionSearchResult{ProjectID: pid, ProjectWorktree: wt, Session: ses, MatchText: ""}
-			if h.Len() < limit {
+			if h.Len() < keep {
 				heap.Push(&h, r)
 				continue
 			}
-			if h.Len() > 0 && r.Session.Updated > h[0].Session.Updated {
+			if h.Len() > 0 && newerFirst(r.Session, h[0].Session) {
 				_ = heap.Pop(&h)
 				heap.Push(&h, r)
 			}
@@ -88,17 +112,25 @@ func (s *JSONStore) RecentSessions(ctx context.Context, limit int) ([]SessionSea
 		out = append(out, heap.Pop(&h).(SessionSearchResult))
 	}
 	// Heap pops oldest-first; sort newest-first.
-	sort.SliceStable(out, func(i, j int) bool { return out[i].Session.Updated > out[j].Session.Updated })
-	if len(out) > limit {
+	sort.SliceStable(out, func(i, j int) bool { return newerFirst(out[i].Session, out[j].Session) })
+	more := len(out) > limit
+	if more {
 		out = out[:limit]
 	}
-	return out, nil
+	if s.CountMessages {
+		// Only the page returned is counted.
+		for i := range out {
+			out[i].Session.Messages = countMessages(s.StorageRoot, out[i].Session.ID)
+		}
+	}
+	return out, more, nil
 }
 
-func (s *JSONStore) SearchSessions(ctx context.Context, query string, limit int) ([]SessionSearchResult, error) {
+func (s *JSONStore) SearchSessions(ctx context.Context, query string, limit int, order SearchOrder) ([]SessionSearchResult, error) {
 	_ = ctx
 	_ = query
 	_ = limit
+	_ = order
 	return nil, fmt.Errorf("session text search requires SQLite (opencode.db)")
 }
 
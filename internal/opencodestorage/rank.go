@@ -0,0 +1,225 @@
+package opencodestorage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Relevance weights. Each signal is normalized to [0,1] before weighting.
+const (
+	rankWeightDensity   = 3.0
+	rankWeightTitle     = 2.0
+	rankWeightProximity = 1.0
+	rankWeightRecency   = 2.0
+
+	// rankDensitySaturation is the match count at which density maxes out.
+	rankDensitySaturation = 20
+	// rankRecencyHalfLife is how long it takes for the recency signal to halve.
+	rankRecencyHalfLife = 14 * 24 * time.Hour
+	// rankProximityScale is the gap (in bytes) between terms that halves the
+	// proximity signal.
+	rankProximityScale = 40.0
+)
+
+// RankSearchResults sorts results by relevance to query, best first.
+//
+// The score blends match density (how many parts matched), title hits (how
+// many query terms appear in the session title), term proximity (how close the
+// terms appear to each other in the best snippet) and a recency decay on the
+// session update time. Ties keep newest-first order.
+func RankSearchResults(results []SessionSearchResult, query string, now time.Time) {
+	terms := SearchTerms(query)
+	if len(terms) == 0 || len(results) < 2 {
+		return
+	}
+	scores := make(map[string]float64, len(results))
+	for _, r := range results {
+		scores[r.ProjectID+"\x00"+r.Session.ID] = relevanceScore(r, terms, now)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		si := scores[results[i].ProjectID+"\x00"+results[i].Session.ID]
+		sj := scores[results[j].ProjectID+"\x00"+results[j].Session.ID]
+		if si != sj {
+			return si > sj
+		}
+		return results[i].Session.Updated > results[j].Session.Updated
+	})
+}
+
+func relevanceScore(r SessionSearchResult, terms []string, now time.Time) float64 {
+	return baseRelevance(r, terms, now) + rankWeightProximity*bestProximity(r, terms)
+}
+
+// baseRelevance is the relevance score without the proximity signal, which is
+// the only one that needs the match snippets.
+func baseRelevance(r SessionSearchResult, terms []string, now time.Time) float64 {
+	count := r.MatchCount
+	if count <= 0 && r.MatchText != "" {
+		count = 1
+	}
+	density := math.Log1p(float64(count)) / math.Log1p(rankDensitySaturation)
+	if density > 1 {
+		density = 1
+	}
+
+	title := strings.ToLower(r.Session.Title)
+	titleHits := 0
+	for _, t := range terms {
+		if strings.Contains(title, t) {
+			titleHits++
+		}
+	}
+	titleScore := float64(titleHits) / float64(len(terms))
+
+	recency := 0.0
+	if r.Session.Updated > 0 {
+		age := now.Sub(time.UnixMilli(r.Session.Updated))
+		if age < 0 {
+			age = 0
+		}
+		recency = math.Pow(0.5, float64(age)/float64(rankRecencyHalfLife))
+	}
+
+	return rankWeightDensity*density +
+		rankWeightTitle*titleScore +
+		rankWeightRecency*recency
+}
+
+// bestProximity is the highest termProximity over the snippets of r.
+func bestProximity(r SessionSearchResult, terms []string) float64 {
+	texts := make([]string, 0, len(r.Matches)+1)
+	for _, m := range r.Matches {
+		texts = append(texts, m.Text)
+	}
+	if len(texts) == 0 && r.MatchText != "" {
+		texts = append(texts, r.MatchText)
+	}
+	proximity := 0.0
+	for _, t := range texts {
+		if p := termProximity(strings.ToLower(t), terms); p > proximity {
+			proximity = p
+		}
+	}
+	return proximity
+}
+
+// rankWindow ranks every result in the window by relevance and returns the
+// best limit of them. load fills in the snippets of a batch; results are
+// loaded in order of their score without proximity, and loading stops once
+// no remaining result can reach the top limit even with full proximity.
+// Results without snippets after loading are dropped.
+func rankWindow(results []SessionSearchResult, query string, limit, batch int, now time.Time, load func([]SessionSearchResult) error) ([]SessionSearchResult, error) {
+	terms := SearchTerms(query)
+	if len(terms) == 0 || limit <= 0 {
+		return []SessionSearchResult{}, nil
+	}
+	if batch < limit {
+		batch = limit
+	}
+	base := make([]float64, len(results))
+	for i, r := range results {
+		base[i] = baseRelevance(r, terms, now)
+	}
+	idx := make([]int, len(results))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		if base[idx[a]] != base[idx[b]] {
+			return base[idx[a]] > base[idx[b]]
+		}
+		return results[idx[a]].Session.Updated > results[idx[b]].Session.Updated
+	})
+
+	kept := make([]SessionSearchResult, 0, limit+batch)
+	for start := 0; start < len(idx); start += batch {
+		if len(kept) >= limit && base[idx[start]]+rankWeightProximity < relevanceScore(kept[limit-1], terms, now) {
+			break
+		}
+		end := start + batch
+		if end > len(idx) {
+			end = len(idx)
+		}
+		page := make([]SessionSearchResult, 0, end-start)
+		for _, i := range idx[start:end] {
+			page = append(page, results[i])
+		}
+		if err := load(page); err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			if len(r.Matches) == 0 {
+				continue
+			}
+			r.MatchText = r.Matches[0].Text
+			kept = append(kept, r)
+		}
+		RankSearchResults(kept, query, now)
+		if len(kept) > limit {
+			kept = kept[:limit]
+		}
+	}
+	return kept, nil
+}
+
+// termProximity scores how tightly all terms cluster in text: 1 when they are
+// adjacent, approaching 0 as the smallest window containing all of them grows.
+// It returns 0 when a term is missing.
+func termProximity(text string, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	type hit struct{ pos, term int }
+	hits := make([]hit, 0, 16)
+	termLen := 0
+	for i, t := range terms {
+		termLen += len(t)
+		found := false
+		for off := 0; ; {
+			j := strings.Index(text[off:], t)
+			if j < 0 {
+				break
+			}
+			hits = append(hits, hit{pos: off + j, term: i})
+			found = true
+			off += j + len(t)
+		}
+		if !found {
+			return 0
+		}
+	}
+	if len(terms) == 1 {
+		return 1
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].pos < hits[j].pos })
+
+	// Minimal window covering every term (two pointers over sorted hits).
+	best := -1
+	counts := make([]int, len(terms))
+	covered := 0
+	lo := 0
+	for hi := range hits {
+		if counts[hits[hi].term] == 0 {
+			covered++
+		}
+		counts[hits[hi].term]++
+		for covered == len(terms) {
+			end := hits[hi].pos + len(terms[hits[hi].term])
+			if w := end - hits[lo].pos; best < 0 || w < best {
+				best = w
+			}
+			counts[hits[lo].term]--
+			if counts[hits[lo].term] == 0 {
+				covered--
+			}
+			lo++
+		}
+	}
+	gap := float64(best - termLen - (len(terms) - 1))
+	if gap < 0 {
+		gap = 0
+	}
+	return 1 / (1 + gap/rankProximityScale)
+}
@@ -0,0 +1,74 @@
+package opencodestorage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankSearchResults_PrefersDenseOlderSessionOverSingleRecentMention(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour).UnixMilli()
+	lastWeek := now.Add(-7 * 24 * time.Hour).UnixMilli()
+
+	results := []SessionSearchResult{
+		{
+			ProjectID:  "p1",
+			Session:    Session{ID: "once", Title: "misc chores", Updated: yesterday},
+			MatchText:  "also touched the cache layer briefly",
+			MatchCount: 1,
+		},
+		{
+			ProjectID:  "p1",
+			Session:    Session{ID: "deep", Title: "cache layer redesign", Updated: lastWeek},
+			MatchText:  "the cache layer needs eviction",
+			MatchCount: 14,
+		},
+	}
+
+	RankSearchResults(results, "cache layer", now)
+	if results[0].Session.ID != "deep" {
+		t.Fatalf("expected dense session first, got %+v", results)
+	}
+}
+
+func TestTermProximity(t *testing.T) {
+	terms := []string{"cache", "eviction"}
+	adjacent := termProximity("cache eviction policy", terms)
+	apart := termProximity("cache "+string(make([]byte, 200))+" eviction", terms)
+	if adjacent != 1 {
+		t.Fatalf("expected adjacent terms to score 1, got %v", adjacent)
+	}
+	if apart >= adjacent || apart <= 0 {
+		t.Fatalf("expected distant terms to score lower, got %v", apart)
+	}
+	if got := termProximity("cache only", terms); got != 0 {
+		t.Fatalf("expected missing term to score 0, got %v", got)
+	}
+}
+
+func TestRankWindow_StopsLoadingOnceTheRestCannotWin(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	results := []SessionSearchResult{
+		{ProjectID: "p1", Session: Session{ID: "stale", Title: "misc", Updated: now.Add(-365 * 24 * time.Hour).UnixMilli()}, MatchCount: 1},
+		{ProjectID: "p1", Session: Session{ID: "best", Title: "cache layer", Updated: now.UnixMilli()}, MatchCount: 20},
+	}
+	var loaded []string
+	load := func(page []SessionSearchResult) error {
+		for i := range page {
+			loaded = append(loaded, page[i].Session.ID)
+			page[i].Matches = []SearchMatch{{Text: "cache layer"}}
+		}
+		return nil
+	}
+
+	got, err := rankWindow(results, "cache layer", 1, 1, now, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Session.ID != "best" || got[0].MatchText != "cache layer" {
+		t.Fatalf("expected best result with its snippet, got %+v", got)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected only the winning result to be loaded, got %v", loaded)
+	}
+}
@@ -7,11 +7,60 @@ import "strings"
 // MatchText is a small chunk of transcript text (typically a single message
 // part) that contains the query; the TUI is responsible for formatting it into
 // a one-line snippet.
+//
+// MatchCount is the total number of matching text parts in the session and
+// Matches holds up to MaxSearchSnippets of them, newest first. MatchText is
+// the text of Matches[0].
 type SessionSearchResult struct {
 	ProjectID       string
 	ProjectWorktree string
 	Session         Session
 	MatchText       string
+	MatchCount      int
+	Matches         []SearchMatch
+}
+
+// SearchMatch is a single matching transcript part.
+type SearchMatch struct {
+	Text    string
+	Created int64 // unix millis
+}
+
+// SearchOrder controls how search results are ordered.
+type SearchOrder int
+
+const (
+	// SearchOrderNewest matches the query as a phrase and orders results by
+	// session update time.
+	SearchOrderNewest SearchOrder = iota
+	// SearchOrderRelevance matches parts containing every query term, in any
+	// order, and ranks every match in the search window by RankSearchResults.
+	SearchOrderRelevance
+)
+
+func (o SearchOrder) String() string {
+	if o == SearchOrderRelevance {
+		return "best match"
+	}
+	return "newest"
+}
+
+// MaxSearchSnippets bounds how many matching parts are returned per session.
+const MaxSearchSnippets = 5
+
+// SearchTerms splits a query into lowercased, de-duplicated terms.
+func SearchTerms(query string) []string {
+	fields := strings.Fields(strings.ToLower(query))
+	out := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		out = append(out, f)
+	}
+	return out
 }
 
 func EscapeLikePattern(s string) string {
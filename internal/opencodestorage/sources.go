@@ -0,0 +1,81 @@
+package opencodestorage
+
+import (
+	"strings"
+	"sync"
+)
+
+// Source names reported by SourceStatus.
+const (
+	SourceSQLite = "sqlite"
+	SourceJSON   = "json"
+)
+
+// SourceStatus describes one data source behind a Store: where it reads from,
+// how much it has read so far and why it is degraded, if it is.
+type SourceStatus struct {
+	Name string // SourceSQLite or SourceJSON
+	Path string
+	// Projects is how many projects the last Projects call read; Sessions
+	// how many sessions were read across the projects loaded so far.
+	Projects int
+	Sessions int
+	// Err is the last read or open error. A composite store keeps working
+	// from its other source, so this is the only place it shows.
+	Err error
+}
+
+// SourceReporter is implemented by stores that can describe their sources.
+type SourceReporter interface {
+	Sources() []SourceStatus
+}
+
+// SourcesOf returns the sources of st, or nil when st doesn't report them.
+func SourcesOf(st Store) []SourceStatus {
+	if r, ok := st.(SourceReporter); ok {
+		return r.Sources()
+	}
+	return nil
+}
+
+// sourceStats counts what a store has read. It is safe for concurrent use:
+// the TUI loads sessions in the background.
+type sourceStats struct {
+	mu       sync.Mutex
+	projects int
+	sessions map[string]int
+	err      error
+}
+
+func (s *sourceStats) recordProjects(n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.projects, s.err = n, nil
+}
+
+func (s *sourceStats) recordSessions(projectID string, n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.err = err
+		return
+	}
+	if s.sessions == nil {
+		s.sessions = map[string]int{}
+	}
+	s.sessions[strings.TrimSpace(projectID)] = n
+}
+
+func (s *sourceStats) status(name, path string) SourceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := SourceStatus{Name: name, Path: path, Projects: s.projects, Err: s.err}
+	for _, n := range s.sessions {
+		st.Sessions += n
+	}
+	return st
+}
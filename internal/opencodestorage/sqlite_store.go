@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -18,29 +19,73 @@ type SQLiteStore struct {
 
 	colsOnce           sync.Once
 	sessionHasArchived bool
-	colsErr            error
+	hasMessageTable    bool
+	// updatedInSeconds is set when time_updated holds unix seconds rather
+	// than millis; see rawUpdated.
+	updatedInSeconds bool
+	colsErr          error
+
+	// CountMessages fills in Session.Messages. Counting adds a subquery per
+	// session, so it is off unless a session policy needs the counts.
+	CountMessages bool
+
+	stats sourceStats
+}
+
+// messageCountExpr counts the messages of the session aliased as alias, or
+// yields -1 when counting is off or the database has no message table.
+func (s *SQLiteStore) messageCountExpr(alias string) string {
+	if !s.CountMessages || !s.hasMessageTable {
+		return "-1"
+	}
+	return `(SELECT COUNT(*) FROM "message" msg WHERE msg.session_id = ` + alias + `.id)`
+}
+
+// rawUpdated converts unix millis back to the unit time_updated is stored in,
+// undoing normalizeUnixMillisFromSQLite, so queries compare and order on the
+// column itself and SQLite can use its index.
+func (s *SQLiteStore) rawUpdated(ms int64) int64 {
+	if s.updatedInSeconds && ms > 0 {
+		return ms / 1000
+	}
+	return ms
 }
 
 func (s *SQLiteStore) RecentSessions(ctx context.Context, limit int) ([]SessionSearchResult, error) {
+	res, _, err := s.RecentSessionsBefore(ctx, RecentCursor{}, limit)
+	return res, err
+}
+
+func (s *SQLiteStore) RecentSessionsBefore(ctx context.Context, cursor RecentCursor, limit int) ([]SessionSearchResult, bool, error) {
 	if limit <= 0 {
-		return []SessionSearchResult{}, nil
+		return []SessionSearchResult{}, false, nil
 	}
 
 	_ = s.ensureSessionColumns(ctx)
 
 	query := `
-		SELECT s.id, s.project_id, s.title, s.directory, s.time_updated, p.worktree
+		SELECT s.id, s.project_id, s.title, s.directory, s.time_updated, p.worktree, ` + s.messageCountExpr("s") + `
 		FROM "session" s
 		JOIN "project" p ON p.id = s.project_id
+		WHERE 1 = 1
 	`
+	var args []any
 	if s.sessionHasArchived {
-		query += ` WHERE IFNULL(s.time_archived, 0) = 0`
+		query += ` AND IFNULL(s.time_archived, 0) = 0`
+	}
+	if !cursor.IsZero() {
+		// Keyset pagination: strictly after the cursor's (time_updated, id).
+		updated := s.rawUpdated(cursor.Updated)
+		query += ` AND (s.time_updated < ? OR (s.time_updated = ? AND s.id < ?))`
+		args = append(args, updated, updated, cursor.ID)
 	}
-	query += ` ORDER BY s.time_updated DESC LIMIT ?`
+	// One extra row tells whether another page follows.
+	query += ` ORDER BY s.time_updated DESC, s.id DESC LIMIT ?`
+	args = append(args, limit+1)
 
-	rows, err := s.db.QueryContext(ctx, query, limit)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer rows.Close()
 
@@ -49,11 +94,17 @@ func (s *SQLiteStore) RecentSessions(ctx context.Context, limit int) ([]SessionS
 		capHint = 64
 	}
 	out := make([]SessionSearchResult, 0, capHint)
+	scanned, more := 0, false
 	for rows.Next() {
+		if scanned++; scanned > limit {
+			more = true
+			break
+		}
 		var sesID, projectID, title, dir, worktree string
 		var updated int64
-		if err := rows.Scan(&sesID, &projectID, &title, &dir, &updated, &worktree); err != nil {
-			return nil, err
+		var messages int
+		if err := rows.Scan(&sesID, &projectID, &title, &dir, &updated, &worktree, &messages); err != nil {
+			return nil, false, err
 		}
 		sesID = strings.TrimSpace(sesID)
 		projectID = strings.TrimSpace(projectID)
@@ -69,27 +120,40 @@ func (s *SQLiteStore) RecentSessions(ctx context.Context, limit int) ([]SessionS
 		out = append(out, SessionSearchResult{
 			ProjectID:       projectID,
 			ProjectWorktree: worktree,
-			Session:         Session{ID: sesID, Title: title, Directory: dir, Updated: normalizeUnixMillisFromSQLite(updated)},
+			Session:         Session{ID: sesID, Title: title, Directory: dir, Updated: normalizeUnixMillisFromSQLite(updated), Messages: messages},
 			MatchText:       "",
 		})
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return out, nil
+	return out, more, nil
 }
 
-func (s *SQLiteStore) SearchSessions(ctx context.Context, query string, limit int) ([]SessionSearchResult, error) {
-	return s.SearchSessionsWindow(ctx, query, limit, 0)
+func (s *SQLiteStore) SearchSessions(ctx context.Context, query string, limit int, order SearchOrder) ([]SessionSearchResult, error) {
+	return s.SearchSessionsWindow(ctx, query, limit, 0, order)
 }
 
-func (s *SQLiteStore) SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int) ([]SessionSearchResult, error) {
+// relevancePoolFactor controls how many matches per requested result get
+// their snippets loaded at a time while ranking by relevance.
+const relevancePoolFactor = 4
+
+func (s *SQLiteStore) SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int, order SearchOrder) ([]SessionSearchResult, error) {
 	query = strings.TrimSpace(query)
 	if query == "" || limit <= 0 {
 		return []SessionSearchResult{}, nil
 	}
 
-	like := "%" + EscapeLikePattern(query) + "%"
+	// Newest-first matches the query as a phrase; best-match requires every
+	// term to appear in the same part, in any order.
+	patterns := []string{query}
+	if order == SearchOrderRelevance {
+		patterns = SearchTerms(query)
+	}
+	likes := make([]any, 0, len(patterns))
+	for _, p := range patterns {
+		likes = append(likes, "%"+EscapeLikePattern(p)+"%")
+	}
 
 	// Avoid scanning the entire DB on each keystroke: search within a window of
 	// most-recently-updated sessions.
@@ -109,41 +173,59 @@ func (s *SQLiteStore) SearchSessionsWindow(ctx context.Context, query string, li
 		candidateLimit = 5000
 	}
 
+	// Best match ranks every match in the window, not just the newest ones.
+	fetchLimit := limit
+	if order == SearchOrderRelevance {
+		fetchLimit = candidateLimit
+	}
+
+	args := make([]any, 0, 2*len(likes)+2)
+	args = append(args, candidateLimit)
+	args = append(args, likes...)
+	args = append(args, fetchLimit)
+	args = append(args, likes...)
+
+	// EXISTS stops at the first matching part; parts are only counted for the
+	// sessions that make it past the LIMIT.
 	rows, err := s.db.QueryContext(ctx, `
 		WITH candidates AS (
 			SELECT id, project_id, title, directory, time_updated
 			FROM "session"
 			ORDER BY time_updated DESC
 			LIMIT ?
-		)
-		SELECT s.id, s.project_id, s.title, s.directory, s.time_updated, p.worktree,
-			(
-				SELECT substr(json_extract(pt.data, '$.text'), 1, 20000)
-				FROM "part" pt
-				WHERE pt.session_id = s.id
-				  AND json_extract(pt.data, '$.type') = 'text'
-				  AND json_extract(pt.data, '$.text') LIKE ? ESCAPE '\'
-				ORDER BY pt.time_created DESC
+		),
+		matched AS (
+			SELECT c.id, c.project_id, c.title, c.directory, c.time_updated, p.worktree
+			FROM candidates c
+			JOIN "project" p ON p.id = c.project_id
+			WHERE EXISTS (
+				SELECT 1
+				FROM "part" px
+				WHERE px.session_id = c.id
+				  AND json_extract(px.data, '$.type') = 'text'
+				  AND `+textLikeClause("px", len(likes))+`
 				LIMIT 1
-			) AS match_text
-		FROM candidates s
-		JOIN "project" p ON p.id = s.project_id
-		WHERE EXISTS (
-			SELECT 1
-			FROM "part" px
-			WHERE px.session_id = s.id
-			  AND json_extract(px.data, '$.type') = 'text'
-			  AND json_extract(px.data, '$.text') LIKE ? ESCAPE '\'
+			)
+			ORDER BY c.time_updated DESC
+			LIMIT ?
 		)
+		SELECT s.id, s.project_id, s.title, s.directory, s.time_updated, s.worktree,
+			(
+				SELECT COUNT(*)
+				FROM "part" px
+				WHERE px.session_id = s.id
+				  AND json_extract(px.data, '$.type') = 'text'
+				  AND `+textLikeClause("px", len(likes))+`
+			) AS match_count
+		FROM matched s
 		ORDER BY s.time_updated DESC
-		LIMIT ?
-	`, candidateLimit, like, like, limit)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	capHint := limit
+	capHint := fetchLimit
 	if capHint > 64 {
 		capHint = 64
 	}
@@ -151,8 +233,8 @@ func (s *SQLiteStore) SearchSessionsWindow(ctx context.Context, query string, li
 	for rows.Next() {
 		var sesID, projectID, title, dir, worktree string
 		var updated int64
-		var match sql.NullString
-		if err := rows.Scan(&sesID, &projectID, &title, &dir, &updated, &worktree, &match); err != nil {
+		var count int
+		if err := rows.Scan(&sesID, &projectID, &title, &dir, &updated, &worktree, &count); err != nil {
 			return nil, err
 		}
 		sesID = strings.TrimSpace(sesID)
@@ -166,22 +248,102 @@ func (s *SQLiteStore) SearchSessionsWindow(ctx context.Context, query string, li
 		}
 		dir = strings.TrimSpace(dir)
 		worktree = strings.TrimSpace(worktree)
-
-		matchText := strings.TrimSpace(match.String)
-		if matchText == "" {
-			continue
-		}
 		out = append(out, SessionSearchResult{
 			ProjectID:       projectID,
 			ProjectWorktree: worktree,
 			Session:         Session{ID: sesID, Title: title, Directory: dir, Updated: normalizeUnixMillisFromSQLite(updated)},
-			MatchText:       matchText,
+			MatchCount:      count,
 		})
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return out, nil
+	rows.Close()
+
+	if order == SearchOrderRelevance {
+		return rankWindow(out, query, limit, limit*relevancePoolFactor, time.Now(), func(page []SessionSearchResult) error {
+			return s.loadSearchMatches(ctx, page, likes)
+		})
+	}
+	if err := s.loadSearchMatches(ctx, out, likes); err != nil {
+		return nil, err
+	}
+	// Drop sessions whose matches vanished between the two queries.
+	filtered := out[:0]
+	for _, r := range out {
+		if len(r.Matches) == 0 {
+			continue
+		}
+		r.MatchText = r.Matches[0].Text
+		filtered = append(filtered, r)
+	}
+	return filtered, nil
+}
+
+// textLikeClause ANDs n LIKE conditions on the text of part alias.
+func textLikeClause(alias string, n int) string {
+	conds := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		conds = append(conds, "json_extract("+alias+".data, '$.text') LIKE ? ESCAPE '\\'")
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// loadSearchMatches fills Matches with the newest MaxSearchSnippets matching
+// text parts of each result.
+func (s *SQLiteStore) loadSearchMatches(ctx context.Context, results []SessionSearchResult, likes []any) error {
+	if len(results) == 0 {
+		return nil
+	}
+	idx := make(map[string]int, len(results))
+	args := make([]any, 0, len(results)+len(likes)+1)
+	args = append(args, likes...)
+	placeholders := make([]string, 0, len(results))
+	for i, r := range results {
+		idx[r.Session.ID] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, r.Session.ID)
+	}
+	args = append(args, MaxSearchSnippets)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, match_text, time_created
+		FROM (
+			SELECT pt.session_id,
+				substr(json_extract(pt.data, '$.text'), 1, 20000) AS match_text,
+				pt.time_created,
+				ROW_NUMBER() OVER (PARTITION BY pt.session_id ORDER BY pt.time_created DESC) AS rn
+			FROM "part" pt
+			WHERE json_extract(pt.data, '$.type') = 'text'
+			  AND `+textLikeClause("pt", len(likes))+`
+			  AND pt.session_id IN (`+strings.Join(placeholders, ",")+`)
+		)
+		WHERE rn <= ?
+		ORDER BY session_id, rn
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sesID string
+		var text sql.NullString
+		var created int64
+		if err := rows.Scan(&sesID, &text, &created); err != nil {
+			return err
+		}
+		i, ok := idx[strings.TrimSpace(sesID)]
+		if !ok {
+			continue
+		}
+		t := strings.TrimSpace(text.String)
+		if t == "" {
+			continue
+		}
+		results[i].Matches = append(results[i].Matches, SearchMatch{Text: t, Created: normalizeUnixMillisFromSQLite(created)})
+	}
+	return rows.Err()
 }
 
 func OpenSQLiteStore(dbPath string) (*SQLiteStore, error) {
@@ -221,6 +383,12 @@ func OpenSQLiteStore(dbPath string) (*SQLiteStore, error) {
 }
 
 func (s *SQLiteStore) Projects(ctx context.Context) ([]Project, error) {
+	projects, err := s.projects(ctx)
+	s.stats.recordProjects(len(projects), err)
+	return projects, err
+}
+
+func (s *SQLiteStore) projects(ctx context.Context) ([]Project, error) {
 	rows, err := s.db.QueryContext(ctx, `SELECT id, worktree, time_updated FROM "project" ORDER BY time_updated DESC`)
 	if err != nil {
 		return nil, err
@@ -248,6 +416,12 @@ func (s *SQLiteStore) Projects(ctx context.Context) ([]Project, error) {
 }
 
 func (s *SQLiteStore) Sessions(ctx context.Context, projectID string) ([]Session, error) {
+	sessions, err := s.sessions(ctx, projectID)
+	s.stats.recordSessions(projectID, len(sessions), err)
+	return sessions, err
+}
+
+func (s *SQLiteStore) sessions(ctx context.Context, projectID string) ([]Session, error) {
 	projectID = strings.TrimSpace(projectID)
 	if projectID == "" {
 		return nil, fmt.Errorf("empty project id")
@@ -258,7 +432,7 @@ func (s *SQLiteStore) Sessions(ctx context.Context, projectID string) ([]Session
 		// (We currently don't filter on archived; this is just schema tolerance.)
 	}
 
-	query := `SELECT id, title, directory, time_updated FROM "session" WHERE project_id = ? ORDER BY time_updated DESC`
+	query := `SELECT s.id, s.title, s.directory, s.time_updated, ` + s.messageCountExpr("s") + ` FROM "session" s WHERE s.project_id = ? ORDER BY s.time_updated DESC`
 	rows, err := s.db.QueryContext(ctx, query, projectID)
 	if err != nil {
 		return nil, err
@@ -269,7 +443,8 @@ func (s *SQLiteStore) Sessions(ctx context.Context, projectID string) ([]Session
 	for rows.Next() {
 		var id, title, dir string
 		var updated int64
-		if err := rows.Scan(&id, &title, &dir, &updated); err != nil {
+		var messages int
+		if err := rows.Scan(&id, &title, &dir, &updated, &messages); err != nil {
 			return nil, err
 		}
 		id = strings.TrimSpace(id)
@@ -281,7 +456,7 @@ func (s *SQLiteStore) Sessions(ctx context.Context, projectID string) ([]Session
 			title = "untitled"
 		}
 		dir = strings.TrimSpace(dir)
-		sessions = append(sessions, Session{ID: id, Title: title, Directory: dir, Updated: normalizeUnixMillisFromSQLite(updated)})
+		sessions = append(sessions, Session{ID: id, Title: title, Directory: dir, Updated: normalizeUnixMillisFromSQLite(updated), Messages: messages})
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -312,11 +487,27 @@ func (s *SQLiteStore) ensureSessionColumns(ctx context.Context) error {
 				s.sessionHasArchived = true
 			}
 		}
-		s.colsErr = rows.Err()
+		if s.colsErr = rows.Err(); s.colsErr != nil {
+			return
+		}
+		var n int
+		if s.colsErr = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'message'`).Scan(&n); s.colsErr != nil {
+			return
+		}
+		s.hasMessageTable = n > 0
+		// A database stores one unit; the newest session tells which.
+		var newest int64
+		s.colsErr = s.db.QueryRowContext(ctx, `SELECT IFNULL(MAX(time_updated), 0) FROM "session"`).Scan(&newest)
+		s.updatedInSeconds = newest > 0 && normalizeUnixMillisFromSQLite(newest) != newest
 	})
 	return s.colsErr
 }
 
+// Sources reports the database file and what was read from it.
+func (s *SQLiteStore) Sources() []SourceStatus {
+	return []SourceStatus{s.stats.status(SourceSQLite, s.dbPath)}
+}
+
 func (s *SQLiteStore) Close() error {
 	if s == nil || s.db == nil {
 		return nil
@@ -3,9 +3,11 @@ package opencodestorage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"net/url"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func createTestSQLiteDB(t *testing.T) string {
@@ -27,6 +29,7 @@ func createTestSQLiteDB(t *testing.T) string {
 	stmts := []string{
 		`CREATE TABLE "project" (id TEXT PRIMARY KEY, worktree TEXT NOT NULL, time_updated INTEGER NOT NULL);`,
 		`CREATE TABLE "session" (id TEXT PRIMARY KEY, project_id TEXT NOT NULL, title TEXT NOT NULL, directory TEXT NOT NULL, time_updated INTEGER NOT NULL);`,
+		`CREATE TABLE "part" (id TEXT PRIMARY KEY, session_id TEXT NOT NULL, time_created INTEGER NOT NULL, data TEXT NOT NULL);`,
 	}
 	for _, s := range stmts {
 		if _, err := db.Exec(s); err != nil {
@@ -98,6 +101,9 @@ func TestSQLiteStore_LoadsProjectsAndSessionsAndNormalizesTimestamps(t *testing.
 	if sessions[1].Directory != "/" {
 		t.Fatalf("expected directory to be parsed: %+v", sessions[1])
 	}
+	if sessions[0].Messages != -1 {
+		t.Fatalf("expected unknown message count without a message table: %+v", sessions[0])
+	}
 }
 
 func TestSQLiteStore_RecentSessions_NewestFirst_ExcludesArchivedWhenPresent(t *testing.T) {
@@ -151,3 +157,233 @@ func TestSQLiteStore_RecentSessions_NewestFirst_ExcludesArchivedWhenPresent(t *t
 		t.Fatalf("expected millis normalization, got %+v", res)
 	}
 }
+
+func TestSQLiteStore_SearchSessions_CountsMatchesAndReturnsNewestSnippets(t *testing.T) {
+	dbPath := createTestSQLiteDB(t)
+
+	{
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		if _, err := db.Exec(`INSERT INTO "project" (id, worktree, time_updated) VALUES (?, ?, ?)`, "p1", "/p1", int64(10)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES (?, ?, ?, ?, ?)`, "s1", "p1", "deep", "/", int64(2)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES (?, ?, ?, ?, ?)`, "s2", "p1", "once", "/", int64(3)); err != nil {
+			t.Fatal(err)
+		}
+		parts := []struct {
+			id, session string
+			created     int64
+			data        string
+		}{
+			{"a1", "s1", 1, `{"type":"text","text":"about Widgets"}`},
+			{"a2", "s1", 2, `{"type":"text","text":"more widgets"}`},
+			{"a3", "s1", 3, `{"type":"tool","text":"widgets in a tool call"}`},
+			{"a4", "s1", 4, `{"type":"text","text":"unrelated"}`},
+			{"a5", "s1", 5, `{"type":"text","text":"last widgets mention"}`},
+			{"b1", "s2", 6, `{"type":"text","text":"one widgets"}`},
+		}
+		for _, pt := range parts {
+			if _, err := db.Exec(`INSERT INTO "part" (id, session_id, time_created, data) VALUES (?, ?, ?, ?)`, pt.id, pt.session, pt.created, pt.data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	st, err := OpenSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	res, err := st.SearchSessions(context.Background(), "widgets", 10, SearchOrderNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(res), res)
+	}
+	if res[0].Session.ID != "s2" || res[0].MatchCount != 1 {
+		t.Fatalf("unexpected first result: %+v", res[0])
+	}
+	deep := res[1]
+	if deep.Session.ID != "s1" || deep.MatchCount != 3 || len(deep.Matches) != 3 {
+		t.Fatalf("expected 3 text matches for s1, got %+v", deep)
+	}
+	if deep.Matches[0].Text != "last widgets mention" || deep.MatchText != deep.Matches[0].Text {
+		t.Fatalf("expected newest match first, got %+v", deep.Matches)
+	}
+	if deep.Matches[0].Created != 5000 {
+		t.Fatalf("expected millis normalization of match time, got %d", deep.Matches[0].Created)
+	}
+
+	// Best-match ordering matches terms in any order within a part.
+	ranked, err := st.SearchSessions(context.Background(), "mention widgets", 10, SearchOrderRelevance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 1 || ranked[0].Session.ID != "s1" || ranked[0].MatchCount != 1 {
+		t.Fatalf("expected term match in s1 only, got %+v", ranked)
+	}
+}
+
+func TestSQLiteStore_SearchSessions_BestMatchRanksWholeWindow(t *testing.T) {
+	dbPath := createTestSQLiteDB(t)
+	now := time.Now()
+	{
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		if _, err := db.Exec(`INSERT INTO "project" (id, worktree, time_updated) VALUES ('p1', '/p1', 1)`); err != nil {
+			t.Fatal(err)
+		}
+		insert := func(id, title string, updated time.Time, texts ...string) {
+			t.Helper()
+			if _, err := db.Exec(`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES (?, 'p1', ?, '/p1', ?)`, id, title, updated.UnixMilli()); err != nil {
+				t.Fatal(err)
+			}
+			for i, text := range texts {
+				data := `{"type":"text","text":"` + text + `"}`
+				if _, err := db.Exec(`INSERT INTO "part" (id, session_id, time_created, data) VALUES (?, ?, ?, ?)`, fmt.Sprintf("%s-%d", id, i), id, updated.UnixMilli(), data); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		// The strong match is older than the newest limit*relevancePoolFactor matches.
+		deep := make([]string, 15)
+		for i := range deep {
+			deep[i] = "the cache layer needs eviction"
+		}
+		insert("deep", "cache layer redesign", now.Add(-60*24*time.Hour), deep...)
+		for i := 0; i < 12; i++ {
+			insert(fmt.Sprintf("new%02d", i), "chores", now.Add(-time.Duration(i)*time.Minute), "cache work, layer later")
+		}
+	}
+
+	st, err := OpenSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	res, err := st.SearchSessions(context.Background(), "cache layer", 2, SearchOrderRelevance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Session.ID != "deep" || len(res[0].Matches) == 0 {
+		t.Fatalf("expected the older dense session first, got %+v", res)
+	}
+}
+
+func TestSQLiteStore_CountsMessagesWhenTableExists(t *testing.T) {
+	dbPath := createTestSQLiteDB(t)
+	{
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		stmts := []string{
+			`CREATE TABLE "message" (id TEXT PRIMARY KEY, session_id TEXT NOT NULL);`,
+			`INSERT INTO "project" (id, worktree, time_updated) VALUES ('p1', '/p1', 1)`,
+			`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES ('s1', 'p1', 'Empty', '/p1', 1)`,
+			`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES ('s2', 'p1', 'Chat', '/p1', 2)`,
+			`INSERT INTO "message" (id, session_id) VALUES ('m1', 's2'), ('m2', 's2')`,
+		}
+		for _, s := range stmts {
+			if _, err := db.Exec(s); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	st, err := OpenSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	sessions, err := st.Sessions(context.Background(), "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Messages != -1 {
+		t.Fatalf("expected no counts unless asked for, got %+v", sessions)
+	}
+
+	st.CountMessages = true
+	sessions, err = st.Sessions(context.Background(), "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Messages != 2 || sessions[1].Messages != 0 {
+		t.Fatalf("unexpected message counts: %+v", sessions)
+	}
+	recent, err := st.RecentSessions(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 || recent[0].Session.Messages != 2 {
+		t.Fatalf("unexpected recent message counts: %+v", recent)
+	}
+}
+
+func TestSQLiteStore_RecentSessionsBefore_PagesWithoutOverlap(t *testing.T) {
+	dbPath := createTestSQLiteDB(t)
+	{
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if _, err := db.Exec(`INSERT INTO "project" (id, worktree, time_updated) VALUES (?, ?, ?)`, "p1", "/p1", int64(10)); err != nil {
+			t.Fatal(err)
+		}
+		// Seconds, with a tie between s2 and s3.
+		for _, s := range []struct {
+			id      string
+			updated int64
+		}{{"s1", 1}, {"s2", 2}, {"s3", 2}, {"s4", 3}, {"s5", 4}} {
+			if _, err := db.Exec(`INSERT INTO "session" (id, project_id, title, directory, time_updated) VALUES (?, ?, ?, ?, ?)`, s.id, "p1", s.id, "/", s.updated); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	st, err := OpenSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	var ids []string
+	var cursor RecentCursor
+	pages := 0
+	for more := true; more && pages < 4; pages++ {
+		var res []SessionSearchResult
+		var err error
+		res, more, err = st.RecentSessionsBefore(context.Background(), cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range res {
+			ids = append(ids, r.Session.ID)
+		}
+		if len(res) > 0 {
+			cursor = res[len(res)-1].Cursor()
+		}
+	}
+	if got := fmt.Sprint(ids); got != "[s5 s4 s3 s2 s1]" || pages != 3 {
+		t.Fatalf("expected every session once, newest first, in 3 pages, got %s in %d", got, pages)
+	}
+}
@@ -20,6 +20,9 @@ type Session struct {
 	Title     string
 	Directory string
 	Updated   int64
+	// Messages is the number of messages in the session, or -1 when the
+	// store cannot tell.
+	Messages int
 }
 
 func LoadProjects(storageRoot string) ([]Project, error) {
@@ -75,6 +78,34 @@ func LoadProjects(storageRoot string) ([]Project, error) {
 	return projects, nil
 }
 
+// CountMessages fills in the message counts of legacy sessions. It reads a
+// directory per session, so LoadSessions leaves the counts unknown (-1) and
+// callers only count when a session policy needs them.
+func CountMessages(storageRoot string, sessions []Session) {
+	for i := range sessions {
+		sessions[i].Messages = countMessages(storageRoot, sessions[i].ID)
+	}
+}
+
+// countMessages counts the message files of a legacy session, or returns -1
+// when the message directory cannot be read.
+func countMessages(storageRoot, sessionID string) int {
+	ents, err := os.ReadDir(filepath.Join(storageRoot, "storage", "message", sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0
+		}
+		return -1
+	}
+	n := 0
+	for _, e := range ents {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") && !strings.HasPrefix(e.Name(), ".") {
+			n++
+		}
+	}
+	return n
+}
+
 func LoadSessions(storageRoot, projectID string) ([]Session, error) {
 	if strings.TrimSpace(projectID) == "" {
 		return nil, fmt.Errorf("empty project id")
@@ -130,7 +161,7 @@ func LoadSessions(storageRoot, projectID string) ([]Session, error) {
 		if updated == 0 {
 			updated = raw.Time.Updated
 		}
-		sessions = append(sessions, Session{ID: raw.ID, Title: title, Directory: dir, Updated: updated})
+		sessions = append(sessions, Session{ID: raw.ID, Title: title, Directory: dir, Updated: updated, Messages: -1})
 	}
 
 	sort.SliceStable(sessions, func(i, j int) bool {
@@ -121,4 +121,45 @@ func TestJSONStore_RecentSessions_NewestFirst_RespectsLimit(t *testing.T) {
 	if res[0].ProjectWorktree != "/p2" || res[1].ProjectWorktree != "/p1" {
 		t.Fatalf("unexpected project worktrees: %+v", res)
 	}
+
+	if _, more, err := st.RecentSessionsBefore(context.Background(), RecentCursor{}, 2); err != nil || !more {
+		t.Fatalf("expected more after the first page, got more=%v (%v)", more, err)
+	}
+	next, more, err := st.RecentSessionsBefore(context.Background(), res[1].Cursor(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 1 || next[0].Session.ID != "s1" || more {
+		t.Fatalf("expected the last page to hold only s1, got %+v (more=%v)", next, more)
+	}
+}
+
+func TestJSONStore_CountsMessagesOnlyWhenAsked(t *testing.T) {
+	root := t.TempDir()
+	writeJSONProject(t, root, "p1.json", `{"id":"p1","worktree":"/p1","time":{"updated":1}}`)
+	writeJSONSession(t, root, "p1", "s1.json", `{"id":"s1","time":{"updated":1}}`)
+	msgDir := filepath.Join(root, "storage", "message", "s1")
+	if err := os.MkdirAll(msgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"m1.json", "m2.json"} {
+		if err := os.WriteFile(filepath.Join(msgDir, name), []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st := NewJSONStore(root)
+	sessions, err := st.Sessions(context.Background(), "p1")
+	if err != nil || len(sessions) != 1 || sessions[0].Messages != -1 {
+		t.Fatalf("expected an unknown count without CountMessages, got %+v (%v)", sessions, err)
+	}
+	st.CountMessages = true
+	sessions, err = st.Sessions(context.Background(), "p1")
+	if err != nil || len(sessions) != 1 || sessions[0].Messages != 2 {
+		t.Fatalf("expected 2 messages, got %+v (%v)", sessions, err)
+	}
+	recent, err := st.RecentSessions(context.Background(), 10)
+	if err != nil || len(recent) != 1 || recent[0].Session.Messages != 2 {
+		t.Fatalf("expected 2 messages in recent sessions, got %+v (%v)", recent, err)
+	}
 }
@@ -10,7 +10,15 @@ type Store interface {
 	// RecentSessions returns a cross-project, newest-first list of sessions.
 	// MatchText is empty.
 	RecentSessions(ctx context.Context, limit int) ([]SessionSearchResult, error)
-	SearchSessions(ctx context.Context, query string, limit int) ([]SessionSearchResult, error)
+	// RecentSessionsBefore pages through RecentSessions: it returns up to limit
+	// sessions that come after cursor in newest-first order. The zero cursor
+	// starts at the newest session; pass the last result's Cursor() to get
+	// the next page. more reports whether sessions remain after the page,
+	// which may be short even then, e.g. when duplicates were dropped.
+	RecentSessionsBefore(ctx context.Context, cursor RecentCursor, limit int) (page []SessionSearchResult, more bool, err error)
+	// SearchSessions returns sessions whose transcript matches query, ordered
+	// according to order.
+	SearchSessions(ctx context.Context, query string, limit int, order SearchOrder) ([]SessionSearchResult, error)
 	Close() error
 }
 
@@ -20,5 +28,36 @@ type Store interface {
 // candidateLimit controls how many sessions are considered (newest-first).
 // If candidateLimit <= 0, the implementation should pick a sensible default.
 type WindowSearchStore interface {
-	SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int) ([]SessionSearchResult, error)
+	SearchSessionsWindow(ctx context.Context, query string, limit int, candidateLimit int, order SearchOrder) ([]SessionSearchResult, error)
+}
+
+// RecentCursor is a position in the recent sessions order: newest Updated
+// first, ties broken by descending session ID so pages never overlap.
+type RecentCursor struct {
+	Updated int64 // unix millis
+	ID      string
+}
+
+// IsZero reports whether c is the start of the list.
+func (c RecentCursor) IsZero() bool { return c == RecentCursor{} }
+
+// Cursor returns the position right after r.
+func (r SessionSearchResult) Cursor() RecentCursor {
+	return RecentCursor{Updated: r.Session.Updated, ID: r.Session.ID}
+}
+
+// admits reports whether s comes after c in the recent sessions order.
+func (c RecentCursor) admits(s Session) bool {
+	if c.IsZero() {
+		return true
+	}
+	return s.Updated < c.Updated || (s.Updated == c.Updated && s.ID < c.ID)
+}
+
+// newerFirst orders sessions the way recent sessions are listed.
+func newerFirst(a, b Session) bool {
+	if a.Updated != b.Updated {
+		return a.Updated > b.Updated
+	}
+	return a.ID > b.ID
 }
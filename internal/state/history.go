@@ -0,0 +1,131 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxLaunches is how many launches are kept; older ones are dropped when the
+// file grows to twice that.
+const maxLaunches = 1000
+
+// Launch is one recorded launch of opencode.
+type Launch struct {
+	Time      time.Time `json:"time"`
+	Project   string    `json:"project"`
+	SessionID string    `json:"session,omitempty"`
+	Model     string    `json:"model"`
+}
+
+func historyPath(dir string) string { return filepath.Join(dir, "history.jsonl") }
+
+// LoadHistory returns the most recent launches from dir, oldest first. A
+// missing file means no history; malformed lines are skipped.
+func LoadHistory(dir string) ([]Launch, error) {
+	f, err := os.Open(historyPath(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Launch
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var l Launch
+		if json.Unmarshal([]byte(line), &l) == nil && l.Project != "" {
+			out = append(out, l)
+		}
+	}
+	if len(out) > maxLaunches {
+		out = out[len(out)-maxLaunches:]
+	}
+	return out, sc.Err()
+}
+
+// AppendLaunch records l in dir's history file.
+func AppendLaunch(dir string, l Launch) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if n, err := countLines(historyPath(dir)); err == nil && n >= 2*maxLaunches {
+		return compactHistory(dir, l)
+	}
+	f, err := os.OpenFile(historyPath(dir), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// compactHistory rewrites the history with the newest launches plus l.
+func compactHistory(dir string, l Launch) error {
+	launches, err := LoadHistory(dir)
+	if err != nil {
+		return err
+	}
+	if len(launches) >= maxLaunches {
+		launches = launches[1:]
+	}
+	launches = append(launches, l)
+	var b strings.Builder
+	for _, x := range launches {
+		line, err := json.Marshal(x)
+		if err != nil {
+			return err
+		}
+		b.Write(append(line, '\n'))
+	}
+	return writeFile(historyPath(dir), []byte(b.String()))
+}
+
+func countLines(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strings.Count(string(b), "\n"), nil
+}
+
+// Frecency scores launches by key the way z and zoxide do: every launch
+// counts, weighted by how recent it is. Empty keys are ignored.
+func Frecency(launches []Launch, key func(Launch) string, now time.Time) map[string]float64 {
+	scores := map[string]float64{}
+	for _, l := range launches {
+		k := key(l)
+		if k == "" {
+			continue
+		}
+		age := now.Sub(l.Time)
+		switch {
+		case age < time.Hour:
+			scores[k] += 4
+		case age < 24*time.Hour:
+			scores[k] += 2
+		case age < 7*24*time.Hour:
+			scores[k] += 0.5
+		default:
+			scores[k] += 0.25
+		}
+	}
+	return scores
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Note is oc's own annotation of a session: tags and a one-line note. OpenCode
+// never sees it.
+type Note struct {
+	Tags []string `json:"tags,omitempty"`
+	Text string   `json:"note,omitempty"`
+}
+
+// IsZero reports whether the note has no tags and no text.
+func (n Note) IsZero() bool { return len(n.Tags) == 0 && n.Text == "" }
+
+// HasTagPrefix reports whether a tag starts with prefix (case-insensitive), so
+// "#tick" finds "ticket-123" while typing.
+func (n Note) HasTagPrefix(prefix string) bool {
+	prefix = normalizeTag(prefix)
+	for _, t := range n.Tags {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notes are the session notes keyed by session ID.
+type Notes struct {
+	Sessions map[string]Note `json:"sessions,omitempty"`
+
+	path string
+}
+
+// LoadNotes reads notes.json from dir; a missing file means no notes.
+func LoadNotes(dir string) (*Notes, error) {
+	n := &Notes{path: filepath.Join(dir, "notes.json")}
+	if err := readJSON(n.path, n); err != nil {
+		return &Notes{path: n.path}, err
+	}
+	return n, nil
+}
+
+// Save writes the notes back to the file they were loaded from.
+func (n *Notes) Save() error { return writeJSON(n.path, n) }
+
+// Get returns the note of a session; a nil *Notes has none.
+func (n *Notes) Get(id string) Note {
+	if n == nil {
+		return Note{}
+	}
+	return n.Sessions[id]
+}
+
+// Set replaces the note of a session; an empty note removes it. Tags are
+// normalized and deduplicated.
+func (n *Notes) Set(id string, note Note) {
+	note.Tags = ParseTags(strings.Join(note.Tags, " "))
+	note.Text = strings.TrimSpace(note.Text)
+	if note.IsZero() {
+		delete(n.Sessions, id)
+		return
+	}
+	if n.Sessions == nil {
+		n.Sessions = map[string]Note{}
+	}
+	n.Sessions[id] = note
+}
+
+// ParseTags splits s on spaces and commas into lowercase tags without a
+// leading "#", dropping duplicates.
+func ParseTags(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if t := normalizeTag(f); t != "" && indexOf(out, t) < 0 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func normalizeTag(t string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(t), "#"))
+}
@@ -0,0 +1,59 @@
+package state
+
+import "path/filepath"
+
+// Pins are the projects (by worktree) and sessions (by ID) pinned to the top
+// of their lists, in the order they were pinned.
+type Pins struct {
+	Projects []string `json:"projects,omitempty"`
+	Sessions []string `json:"sessions,omitempty"`
+
+	path string
+}
+
+// LoadPins reads pins.json from dir; a missing file means no pins.
+func LoadPins(dir string) (*Pins, error) {
+	p := &Pins{path: filepath.Join(dir, "pins.json")}
+	if err := readJSON(p.path, p); err != nil {
+		return &Pins{path: p.path}, err
+	}
+	return p, nil
+}
+
+// Save writes the pins back to the file they were loaded from.
+func (p *Pins) Save() error { return writeJSON(p.path, p) }
+
+// ProjectPinned reports whether worktree is pinned; a nil *Pins pins nothing.
+func (p *Pins) ProjectPinned(worktree string) bool {
+	return p != nil && indexOf(p.Projects, worktree) >= 0
+}
+
+// SessionPinned reports whether the session is pinned; a nil *Pins pins
+// nothing.
+func (p *Pins) SessionPinned(id string) bool {
+	return p != nil && indexOf(p.Sessions, id) >= 0
+}
+
+// ToggleProject pins or unpins a project and reports whether it is pinned now.
+func (p *Pins) ToggleProject(worktree string) bool { return toggle(&p.Projects, worktree) }
+
+// ToggleSession pins or unpins a session and reports whether it is pinned now.
+func (p *Pins) ToggleSession(id string) bool { return toggle(&p.Sessions, id) }
+
+func toggle(list *[]string, s string) bool {
+	if i := indexOf(*list, s); i >= 0 {
+		*list = append((*list)[:i], (*list)[i+1:]...)
+		return false
+	}
+	*list = append(*list, s)
+	return true
+}
+
+func indexOf(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,117 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SavedSearch is a global search query stored under a name.
+type SavedSearch struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Searches are the recent global search queries, newest first, and the named
+// saved searches, in the order they were saved.
+type Searches struct {
+	History []string      `json:"history,omitempty"`
+	Saved   []SavedSearch `json:"saved,omitempty"`
+
+	path string
+}
+
+// LoadSearches reads searches.json from dir; a missing file means no history
+// and no saved searches.
+func LoadSearches(dir string) (*Searches, error) {
+	s := &Searches{path: filepath.Join(dir, "searches.json")}
+	if err := readJSON(s.path, s); err != nil {
+		return &Searches{path: s.path}, err
+	}
+	return s, nil
+}
+
+// Save writes the searches back to the file they were loaded from.
+func (s *Searches) Save() error { return writeJSON(s.path, s) }
+
+// Recent returns the search history, newest first; a nil *Searches has none.
+func (s *Searches) Recent() []string {
+	if s == nil {
+		return nil
+	}
+	return s.History
+}
+
+// AddHistory moves q to the front of the history and keeps at most max
+// queries. Blank queries are ignored; max <= 0 clears the history.
+func (s *Searches) AddHistory(q string, max int) {
+	if max <= 0 {
+		s.History = nil
+		return
+	}
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return
+	}
+	if i := indexOf(s.History, q); i >= 0 {
+		s.History = append(s.History[:i], s.History[i+1:]...)
+	}
+	s.History = append([]string{q}, s.History...)
+	if len(s.History) > max {
+		s.History = s.History[:max]
+	}
+}
+
+// Find returns the saved search called name (case-insensitive).
+func (s *Searches) Find(name string) (SavedSearch, bool) {
+	if i := s.savedIndex(name); i >= 0 {
+		return s.Saved[i], true
+	}
+	return SavedSearch{}, false
+}
+
+// SaveSearch stores query under name, replacing a saved search of the same
+// name in place. Blank names or queries are ignored.
+func (s *Searches) SaveSearch(name, query string) bool {
+	name, query = strings.TrimSpace(name), strings.TrimSpace(query)
+	if name == "" || query == "" {
+		return false
+	}
+	if i := s.savedIndex(name); i >= 0 {
+		s.Saved[i] = SavedSearch{Name: name, Query: query}
+		return true
+	}
+	s.Saved = append(s.Saved, SavedSearch{Name: name, Query: query})
+	return true
+}
+
+// DeleteSaved removes the saved search called name and reports whether there
+// was one.
+func (s *Searches) DeleteSaved(name string) bool {
+	i := s.savedIndex(name)
+	if i < 0 {
+		return false
+	}
+	s.Saved = append(s.Saved[:i], s.Saved[i+1:]...)
+	return true
+}
+
+// SavedSearches returns the saved searches; a nil *Searches has none.
+func (s *Searches) SavedSearches() []SavedSearch {
+	if s == nil {
+		return nil
+	}
+	return s.Saved
+}
+
+func (s *Searches) savedIndex(name string) int {
+	if s == nil {
+		return -1
+	}
+	name = strings.TrimSpace(name)
+	for i, x := range s.Saved {
+		if strings.EqualFold(x.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,182 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDir_PrefersOverrideThenXDG(t *testing.T) {
+	t.Setenv("OC_STATE_DIR", "")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	if got, _ := Dir(); got != filepath.Join("/xdg/state", "oc") {
+		t.Fatalf("expected XDG state dir, got %q", got)
+	}
+	t.Setenv("OC_STATE_DIR", "/custom")
+	if got, _ := Dir(); got != "/custom" {
+		t.Fatalf("expected override, got %q", got)
+	}
+}
+
+func TestPins_ToggleAndPersist(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "oc")
+	pins, err := LoadPins(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pins.ToggleProject("/src/a") || !pins.ToggleProject("/src/b") || !pins.ToggleSession("s1") {
+		t.Fatalf("expected toggles to pin")
+	}
+	if pins.ToggleProject("/src/a") {
+		t.Fatalf("expected second toggle to unpin")
+	}
+	if err := pins.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := LoadPins(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Projects) != 1 || !again.ProjectPinned("/src/b") || !again.SessionPinned("s1") {
+		t.Fatalf("unexpected pins after reload: %+v", again)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected only pins.json in the state dir, got %d entries", len(entries))
+	}
+}
+
+func TestLoadPins_ReportsCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pins.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pins, err := LoadPins(dir)
+	if err == nil {
+		t.Fatalf("expected a decode error")
+	}
+	if pins == nil || pins.ProjectPinned("/x") {
+		t.Fatalf("expected empty usable pins on error")
+	}
+}
+
+func TestHistory_AppendLoadAndCompact(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2*maxLaunches+1; i++ {
+		if err := AppendLaunch(dir, Launch{Time: start.Add(time.Duration(i) * time.Minute), Project: "/src/a", Model: "openai/gpt-5.2"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	launches, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(launches) != maxLaunches || !launches[len(launches)-1].Time.Equal(start.Add(2*maxLaunches*time.Minute)) {
+		t.Fatalf("expected the newest %d launches, got %d ending %v", maxLaunches, len(launches), launches[len(launches)-1].Time)
+	}
+	if n, _ := countLines(historyPath(dir)); n != maxLaunches {
+		t.Fatalf("expected the file to be compacted to %d lines, got %d", maxLaunches, n)
+	}
+}
+
+func TestFrecency_BlendsFrequencyAndRecency(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var launches []Launch
+	// Ten launches a month ago lose to three today.
+	for i := 0; i < 10; i++ {
+		launches = append(launches, Launch{Time: now.Add(-30 * 24 * time.Hour), Project: "/old"})
+	}
+	for i := 0; i < 3; i++ {
+		launches = append(launches, Launch{Time: now.Add(-2 * time.Hour), Project: "/daily"})
+	}
+	launches = append(launches, Launch{Time: now.Add(-time.Minute), Project: "/once"})
+
+	scores := Frecency(launches, func(l Launch) string { return l.Project }, now)
+	if !(scores["/daily"] > scores["/once"] && scores["/once"] > scores["/old"]) {
+		t.Fatalf("unexpected scores: %v", scores)
+	}
+}
+
+func TestNotes_SetNormalizesAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	notes, err := LoadNotes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes.Set("s1", Note{Tags: []string{"#Ticket-123, spike", "ticket-123"}, Text: "  waiting on review "})
+	notes.Set("s2", Note{Tags: []string{"done"}})
+	notes.Set("s2", Note{})
+	if err := notes.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := LoadNotes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := again.Get("s1")
+	if strings.Join(n.Tags, ",") != "ticket-123,spike" || n.Text != "waiting on review" {
+		t.Fatalf("unexpected note after reload: %+v", n)
+	}
+	if !n.HasTagPrefix("#TICK") || n.HasTagPrefix("done") {
+		t.Fatalf("unexpected tag prefix matching for %+v", n)
+	}
+	if _, ok := again.Sessions["s2"]; ok {
+		t.Fatalf("expected an empty note to be removed")
+	}
+	var none *Notes
+	if !none.Get("s1").IsZero() {
+		t.Fatalf("expected a nil *Notes to have no notes")
+	}
+}
+
+func TestSearches_HistoryDedupesAndCapsSavedPersist(t *testing.T) {
+	dir := t.TempDir()
+	s, err := LoadSearches(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"alpha", "beta", " alpha ", "", "gamma", "delta"} {
+		s.AddHistory(q, 3)
+	}
+	if got := strings.Join(s.Recent(), ","); got != "delta,gamma,alpha" {
+		t.Fatalf("unexpected history: %q", got)
+	}
+	if !s.SaveSearch("Bugs", "panic nil") || !s.SaveSearch("todo", "TODO") || !s.SaveSearch("bugs", "panic") {
+		t.Fatalf("expected saves to succeed")
+	}
+	if s.SaveSearch("empty", "  ") {
+		t.Fatalf("expected a blank query not to be saved")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := LoadSearches(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.SavedSearches()) != 2 {
+		t.Fatalf("expected 2 saved searches, got %+v", again.SavedSearches())
+	}
+	if b, ok := again.Find("BUGS"); !ok || b.Name != "bugs" || b.Query != "panic" {
+		t.Fatalf("expected the same-named search to be replaced in place, got %+v", b)
+	}
+	if !again.DeleteSaved("todo") || again.DeleteSaved("todo") {
+		t.Fatalf("expected todo to be deleted exactly once")
+	}
+	again.AddHistory("epsilon", 0)
+	if len(again.Recent()) != 0 {
+		t.Fatalf("expected a zero cap to clear the history, got %v", again.Recent())
+	}
+	var none *Searches
+	if none.Recent() != nil || none.SavedSearches() != nil {
+		t.Fatalf("expected a nil *Searches to have nothing")
+	}
+	if _, ok := none.Find("bugs"); ok {
+		t.Fatalf("expected a nil *Searches to find nothing")
+	}
+}
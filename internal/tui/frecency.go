@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"oc/internal/config"
+)
+
+// toggleSort switches the model column between config and frecency order
+// when it has focus, otherwise the project column between recency and
+// frecency order. The selection is kept.
+func (m *model) toggleSort() {
+	if m.focus == focusModels {
+		m.frecentModels = !m.frecentModels
+		m.setModelItems(m.modelList.SelectedItem())
+		return
+	}
+	m.frecentProjects = !m.frecentProjects
+	m.applyProjectFilter(false)
+}
+
+// sortProjectsByFrecency orders items by launch frecency, keeping the
+// recency order among ties. General stays first.
+func (m model) sortProjectsByFrecency(items []list.Item) {
+	score := func(it list.Item) float64 {
+		pi, ok := it.(projectItem)
+		if !ok {
+			return 0
+		}
+		return m.projectScores[pi.Worktree]
+	}
+	isGlobal := func(it list.Item) bool {
+		pi, ok := it.(projectItem)
+		return ok && isGlobalProject(pi.Project)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if gi, gj := isGlobal(items[i]), isGlobal(items[j]); gi != gj {
+			return gi
+		}
+		return score(items[i]) > score(items[j])
+	})
+}
+
+// sortModelsByFrecency returns models ordered by launch frecency, keeping the
+// config order among ties.
+func (m model) sortModelsByFrecency(models []config.Model) []config.Model {
+	out := append([]config.Model(nil), models...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return m.modelScores[out[i].Model] > m.modelScores[out[j].Model]
+	})
+	return out
+}
+
+func frecentSuffix(frecent bool) string {
+	if frecent {
+		return " (frecent)"
+	}
+	return ""
+}
+
+// sortBinding is the help entry for the sort key, naming the current order
+// of the column it toggles.
+func (m model) sortBinding() helpBinding {
+	switch {
+	case m.focus == focusModels && m.frecentModels, m.focus != focusModels && m.frecentProjects:
+		return bind(m.keys.Sort, "sort: frecent")
+	case m.focus == focusModels:
+		return bind(m.keys.Sort, "sort: config")
+	}
+	return bind(m.keys.Sort, "sort: updated")
+}
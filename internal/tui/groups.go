@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Date groups of the session lists, newest first. The groups from
+// groupThisMonth on can be collapsed.
+const (
+	groupPinned    = "Pinned"
+	groupToday     = "Today"
+	groupYesterday = "Yesterday"
+	groupThisWeek  = "This week"
+	groupThisMonth = "This month"
+	groupOlder     = "Older"
+)
+
+// groupHeader is a non-selectable section header in a session list.
+type groupHeader struct {
+	label     string
+	count     int
+	dated     bool
+	collapsed bool
+}
+
+func (h groupHeader) Title() string { return h.label }
+
+func (h groupHeader) Description() string {
+	s := fmt.Sprintf("%d sessions", h.count)
+	if h.count == 1 {
+		s = "1 session"
+	}
+	if h.collapsed {
+		s += " (collapsed)"
+	}
+	return s
+}
+
+func (h groupHeader) FilterValue() string { return "" }
+
+// collapsible reports whether a date group folds away when older groups
+// collapse.
+func collapsible(label string) bool {
+	return label == groupThisMonth || label == groupOlder
+}
+
+// dateGroup returns the group of a session updated at ms. Weeks start on
+// Monday; future times count as today.
+func dateGroup(ms int64, now time.Time) string {
+	t := time.UnixMilli(ms).In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday := (int(today.Weekday()) + 6) % 7 // Monday = 0
+	switch {
+	case !t.Before(today):
+		return groupToday
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return groupYesterday
+	case !t.Before(today.AddDate(0, 0, -weekday)):
+		return groupThisWeek
+	case !t.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())):
+		return groupThisMonth
+	}
+	return groupOlder
+}
+
+// groupByDate puts a header before each date group of items, which are ordered
+// newest first. Collapsed groups keep only their header.
+func groupByDate(items []list.Item, updated func(list.Item) int64, now time.Time, collapseOlder bool) []list.Item {
+	out := make([]list.Item, 0, len(items)+5)
+	header := -1
+	for _, it := range items {
+		label := dateGroup(updated(it), now)
+		if header < 0 || out[header].(groupHeader).label != label {
+			header = len(out)
+			out = append(out, groupHeader{label: label, dated: true, collapsed: collapseOlder && collapsible(label)})
+		}
+		h := out[header].(groupHeader)
+		h.count++
+		out[header] = h
+		if !h.collapsed {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// hasCollapsible reports whether items have a group that can be collapsed.
+func hasCollapsible(items []list.Item) bool {
+	for _, it := range items {
+		if h, ok := it.(groupHeader); ok && h.dated && collapsible(h.label) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipHeaders moves the selection off a header, onward in the direction it
+// moved from index from, or back when there is nothing further.
+func skipHeaders(l *list.Model, from int) {
+	items := l.Items()
+	i := l.Index()
+	if i < 0 || i >= len(items) {
+		return
+	}
+	if _, ok := items[i].(groupHeader); !ok {
+		return
+	}
+	dir := 1
+	if i < from {
+		dir = -1
+	}
+	for _, d := range []int{dir, -dir} {
+		for j := i + d; j >= 0 && j < len(items); j += d {
+			if _, ok := items[j].(groupHeader); !ok {
+				l.Select(j)
+				return
+			}
+		}
+	}
+}
+
+func (m model) collapseBinding() helpBinding {
+	if m.collapseOlder {
+		return bind(m.keys.Collapse, "expand older")
+	}
+	return bind(m.keys.Collapse, "collapse older")
+}
@@ -0,0 +1,289 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpOverlay is the full keybinding help for the current view.
+type helpOverlay struct {
+	open   bool
+	offset int
+}
+
+// helpSection is a group of bindings with a common purpose.
+type helpSection struct {
+	title string
+	rows  []helpBinding
+}
+
+// allKeys lists every key bound to b, so config overrides show up as they are.
+func allKeys(b key.Binding) string {
+	if !b.Enabled() || len(b.Keys()) == 0 {
+		return "(unbound)"
+	}
+	return strings.Join(b.Keys(), " / ")
+}
+
+func row(b key.Binding, text string) helpBinding {
+	return helpBinding{key: allKeys(b), text: text}
+}
+
+// canOpenHelp reports whether the help key opens the overlay. A printable help
+// key like "?" only does so while there is no text to type it into.
+func (m model) canOpenHelp(msg tea.KeyMsg) bool {
+	if !key.Matches(msg, m.keys.Help) {
+		return false
+	}
+	if msg.Type != tea.KeyRunes {
+		return true
+	}
+	switch {
+	case m.searchOpen:
+		return strings.TrimSpace(m.searchInput.Value()) == ""
+	case m.viewMode == viewModeRecentSessions:
+		return m.recentFilter.Value() == ""
+	case m.focus == focusProjects:
+		return m.projFilter.Value() == ""
+	case m.focus == focusSessions:
+		return m.sesFilter.Value() == ""
+	}
+	return true
+}
+
+func (m model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := maxInt(1, m.helpVisibleLines())
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.plan = nil
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Help):
+		m.help = helpOverlay{}
+		return m, nil
+	}
+	switch msg.String() {
+	case "up":
+		m.help.offset--
+	case "down":
+		m.help.offset++
+	case "pgup":
+		m.help.offset -= page
+	case "pgdown":
+		m.help.offset += page
+	case "home":
+		m.help.offset = 0
+	case "end":
+		m.help.offset = len(m.helpLines())
+	}
+	m.help.offset = clampInt(m.help.offset, 0, maxInt(0, len(m.helpLines())-page))
+	return m, nil
+}
+
+// helpView names the view the overlay describes.
+func (m model) helpView() string {
+	switch {
+	case m.searchOpen:
+		return "search"
+	case m.viewMode == viewModeRecentSessions:
+		return "recent sessions"
+	}
+	switch m.focus {
+	case focusSessions:
+		return "sessions"
+	case focusModels:
+		return "models"
+	}
+	return "projects"
+}
+
+// helpSections lists every binding of the current view grouped by purpose.
+func (m model) helpSections() []helpSection {
+	k := m.keys
+	move := helpBinding{key: "up / down / pgup / pgdown / home / end", text: "move in the list"}
+	var out []helpSection
+	switch {
+	case m.searchOpen:
+		out = []helpSection{
+			{"Search", []helpBinding{
+				{key: "type", text: "search session titles and messages"},
+				move,
+				row(k.SearchOrder, "toggle newest (phrase) / best match (all terms) order"),
+				row(k.SearchMatches, "show the matches of the selected result"),
+				row(k.NextMatch, "next match snippet"),
+				row(k.PrevMatch, "previous match snippet"),
+			}},
+			{"Launch", []helpBinding{
+				row(k.Launch, "resume the selected session"),
+				row(k.Quit, "quit without launching"),
+			}},
+			{"Views", []helpBinding{
+				row(k.Back, "close search"),
+				row(k.Search, "close search"),
+				row(k.Help, "close this help"),
+			}},
+		}
+		if m.searches != nil {
+			out = append(out, helpSection{"History & saved searches", []helpBinding{
+				{key: "up / down", text: "recall recent queries while the search box is empty"},
+				row(k.SaveSearch, "save the query under a name"),
+				row(k.SaveSearch, "with an empty search box: list the saved searches"),
+				{key: "delete", text: "remove the selected saved search"},
+			}})
+		}
+	case m.viewMode == viewModeRecentSessions:
+		out = []helpSection{
+			{"Sessions", []helpBinding{
+				move,
+				{key: "type", text: "filter by title, project, tags or note"},
+				{key: "#tag", text: "keep sessions with a tag starting with tag"},
+				{key: "scroll past the end", text: "load older sessions"},
+				row(k.Pin, "pin or unpin the selected session"),
+				row(k.EditNote, "edit the tags and note of the selected session"),
+				row(k.Collapse, "collapse or expand the older date groups"),
+				row(k.RevealHidden, "show or hide sessions hidden by the session policy"),
+			}},
+			{"Launch", []helpBinding{
+				row(k.Launch, "resume the selected session"),
+				row(k.Quit, "quit without launching"),
+			}},
+			{"Views", []helpBinding{
+				row(k.Back, "back to projects"),
+				row(k.Projects, "back to projects"),
+				row(k.Recent, "back to projects"),
+				row(k.Search, "global search"),
+				row(k.Help, "close this help"),
+			}},
+		}
+	default:
+		columns := []helpBinding{
+			row(k.NextFocus, "focus the next column"),
+			row(k.PrevFocus, "focus the previous column"),
+			move,
+		}
+		switch m.focus {
+		case focusProjects:
+			columns = append(columns, helpBinding{key: "type", text: "filter projects by name, path or alias"})
+		case focusSessions:
+			columns = append(columns,
+				helpBinding{key: "type", text: "filter sessions"},
+				helpBinding{key: "#tag", text: "keep sessions with a tag starting with tag"})
+		}
+		launch := "open a new session in the selected project"
+		if m.selectedSessionID() != "" {
+			launch = "resume the selected session"
+		}
+		lists := []helpBinding{
+			row(k.Pin, "pin or unpin the selected project or session"),
+			row(k.EditNote, "edit the tags and note of the selected session"),
+			row(k.Sort, "toggle frecency sort for the projects or model column"),
+			row(k.Collapse, "collapse or expand the older date groups of sessions"),
+			row(k.RevealHidden, "show or hide sessions hidden by the session policy"),
+		}
+		out = []helpSection{
+			{"Columns", columns},
+			{"Launch", []helpBinding{
+				row(k.Launch, launch),
+				row(k.Quit, "quit without launching"),
+			}},
+			{"Projects & sessions", lists},
+			{"Views", []helpBinding{
+				row(k.Search, "global search"),
+				row(k.Recent, "recent sessions"),
+				row(k.Help, "close this help"),
+			}},
+		}
+		if m.layoutMode() == layoutModeNarrow {
+			out = append(out, helpSection{"Narrow layout", []helpBinding{
+				{key: allKeys(k.NextFocus), text: "expand the next column; the others show only their title"},
+				{key: "context line", text: "shows the selected project, session and model"},
+			}})
+		}
+	}
+	if m.vim {
+		out = append(out, helpSection{"Vim mode", []helpBinding{
+			{key: "j / k", text: "move down / up"},
+			{key: "g / G", text: "go to the first / last item"},
+			{key: "h / l", text: "focus the previous / next column; in search, step match snippets"},
+			{key: "n / N", text: "next / previous search result"},
+			{key: "/", text: "filter mode: type into the filter or search box"},
+			row(k.Back, "back to normal mode from filter mode"),
+			{key: ":", text: "open the command line"},
+		}}, helpSection{"Commands", vimCommands})
+	}
+	if m.inline && !m.searchOpen && m.viewMode == viewModeProjects {
+		out = append(out, helpSection{"Inline picker", []helpBinding{
+			{key: allKeys(k.NextFocus), text: "next step: project, session, then model"},
+			{key: "title line", text: "shows the project, session and model picked so far"},
+		}})
+	}
+	if m.mouse {
+		out = append(out, helpSection{"Mouse", []helpBinding{
+			{key: "click", text: "focus a column and select an item"},
+			{key: "double-click", text: "launch the item"},
+			{key: "wheel", text: "scroll the list under the pointer"},
+		}})
+	}
+	return out
+}
+
+// helpLines renders the sections with the keys aligned in one column.
+func (m model) helpLines() []string {
+	sections := m.helpSections()
+	keyW := 0
+	for _, s := range sections {
+		for _, r := range s.rows {
+			keyW = maxInt(keyW, len(r.key))
+		}
+	}
+	var lines []string
+	for i, s := range sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.styles.titleIdle.Render(s.title))
+		for _, r := range s.rows {
+			lines = append(lines, "  "+m.styles.key.Render(r.key+strings.Repeat(" ", keyW-len(r.key)))+"  "+m.styles.muted.Render(r.text))
+		}
+	}
+	return lines
+}
+
+// helpVisibleLines is how many help lines fit below the overlay title.
+func (m model) helpVisibleLines() int {
+	if m.inline {
+		return m.inlineRows() + 1
+	}
+	return m.panelHeight - 1
+}
+
+func (m model) helpOverlayHelp() []helpBinding {
+	return []helpBinding{
+		bind(m.keys.Back, "close"),
+		{key: "up/down", text: "scroll"},
+		bind(m.keys.Quit, "quit"),
+	}
+}
+
+func (m model) viewHelp() string {
+	header := m.helpLine(m.helpOverlayHelp(), "")
+
+	fullW := maxInt(20, m.width-outerMarginLeft-outerMarginRight-m.safetySlack())
+	lines := m.helpLines()
+	visible := maxInt(1, m.helpVisibleLines())
+	start := clampInt(m.help.offset, 0, maxInt(0, len(lines)-visible))
+	end := minInt(start+visible, len(lines))
+	for i := start; i < end; i++ {
+		lines[i] = truncateANSI(lines[i], maxInt(10, fullW-2))
+	}
+	title := "Help: " + m.helpView()
+	if len(lines) > visible {
+		title += " (" + strconv.Itoa(end) + "/" + strconv.Itoa(len(lines)) + ")"
+	}
+	content := m.title(title, true) + "\n" + strings.Join(lines[start:end], "\n")
+	panel := m.panelW(true, fullW, m.panelHeight, content)
+
+	return m.screen(header, panel)
+}
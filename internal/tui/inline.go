@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"oc/internal/opencodestorage"
+)
+
+// minInlineHeight is the smallest inline picker: a title, a prompt, two rows
+// and the help line.
+const minInlineHeight = 5
+
+// inlineHeight is how many lines the inline picker takes below the prompt.
+func (m model) inlineHeight() int {
+	h := maxInt(m.inlineLines, minInlineHeight)
+	if m.height > 0 {
+		h = minInt(h, m.height)
+	}
+	return h
+}
+
+// inlineRows is how many list rows fit between the prompt and the help line.
+func (m model) inlineRows() int {
+	return maxInt(1, m.inlineHeight()-3)
+}
+
+func (m model) inlineWidth() int {
+	if m.width <= 0 {
+		return 80
+	}
+	return maxInt(20, m.width-m.safetySlack())
+}
+
+// inlineSearchRows is how many search results fit inline; the expanded
+// matches take the rows below them when there is room.
+func (m model) inlineSearchRows() int {
+	rows := m.inlineRows()
+	if m.searchExpanded && rows >= searchMatchesHeight+2 {
+		rows -= searchMatchesHeight
+	}
+	return rows
+}
+
+// resizeInline sizes the lists so a page is exactly the inline rows; rows
+// are one line each, but pgup/pgdown and paging go by the list's page.
+func (m *model) resizeInline() {
+	w, rows := m.inlineWidth(), m.inlineRows()
+	itemH := 3 // title, description and spacing of the list delegate
+	for _, l := range []*list.Model{&m.projList, &m.sesList, &m.modelList, &m.recentList} {
+		l.SetSize(w, rows*itemH)
+	}
+	m.searchList.SetSize(w, m.inlineSearchRows()*itemH)
+}
+
+// viewInline renders the compact picker: a title, a prompt line, one row per
+// item and the help line, always inlineHeight lines so the scrollback above
+// stays put.
+func (m model) viewInline() string {
+	switch {
+	case m.editor.open:
+		return m.inlineFrame(
+			m.styles.titleActive.Render("Tags & note")+"  "+m.styles.muted.Render(m.editor.title),
+			m.editor.tags.View(),
+			[]string{m.editor.text.View(), m.styles.muted.Render("Separate tags with spaces; filter sessions with #tag.")},
+			m.helpLine(m.noteEditorHelp(), ""))
+	case m.help.open:
+		lines := m.helpLines()
+		visible := m.helpVisibleLines()
+		start := clampInt(m.help.offset, 0, maxInt(0, len(lines)-visible))
+		end := minInt(start+visible, len(lines))
+		title := "Help: " + m.helpView()
+		if len(lines) > visible {
+			title += " (" + strconv.Itoa(end) + "/" + strconv.Itoa(len(lines)) + ")"
+		}
+		return m.inlineFrame(m.styles.titleActive.Render(title), lines[start], lines[start+1:end], m.helpLine(m.helpOverlayHelp(), ""))
+	case m.searchOpen:
+		title := "Search sessions"
+		switch {
+		case m.searchPicking:
+			title = "Saved searches"
+		case m.searchOrder == opencodestorage.SearchOrderRelevance:
+			title += " (best match)"
+		}
+		prompt := m.inlinePrompt("search", m.searchInput.Value(), "type to search")
+		if m.searchNaming {
+			prompt = m.searchName.View()
+		}
+		rows := m.inlineList(m.searchList)
+		if m.searchExpanded && m.inlineSearchRows() < m.inlineRows() {
+			rows = append(padLines(rows, m.inlineSearchRows()), strings.Split(m.viewSearchMatches(m.inlineWidth()), "\n")...)
+		}
+		return m.inlineFrame(m.inlineTitle(title, m.searchStatus()), prompt, rows, m.header(m.searchHelp()))
+	case m.viewMode == viewModeRecentSessions:
+		return m.inlineFrame(
+			m.inlineTitle("Recent sessions"+m.hiddenSuffix(m.recentHidden), m.recentStatus()),
+			m.inlinePrompt("filter", m.recentFilter.Value(), "type to filter"),
+			m.inlineList(m.recentList),
+			m.header(m.recentHelp()))
+	}
+
+	var prompt string
+	var rows []string
+	switch m.focus {
+	case focusProjects:
+		prompt = m.inlinePrompt("project", m.projFilter.Value(), "type to filter")
+		rows = m.inlineList(m.projList)
+	case focusSessions:
+		prompt = m.inlinePrompt("session", m.sesFilter.Value(), "type to filter")
+		rows = m.inlineList(m.sesList)
+	case focusModels:
+		prompt = m.styles.key.Render("model> ") + m.styles.muted.Render("pick a model"+frecentSuffix(m.frecentModels))
+		rows = m.inlineList(m.modelList)
+		if m.modelLocked() {
+			rows = []string{m.styles.muted.Render("Locked by session. To change model, pick 'New session'.")}
+		}
+		if m.modelErr != "" {
+			rows = append([]string{m.styles.muted.Render("config: " + m.modelErr)}, rows...)
+		}
+	}
+	return m.inlineFrame(m.inlineSteps(), prompt, rows, m.header(m.projectsHelp()))
+}
+
+// inlineSteps is the title of the projects view: the project, session and
+// model picked so far, with the focused step highlighted.
+func (m model) inlineSteps() string {
+	steps := []struct {
+		f     focus
+		label string
+	}{
+		{focusProjects, "Project: " + m.selectedProjectLabel()},
+		{focusSessions, "Session: " + m.selectedSessionLabel()},
+		{focusModels, "Model: " + m.selectedModelLabel()},
+	}
+	parts := make([]string, 0, len(steps))
+	for _, s := range steps {
+		if s.f == m.focus {
+			parts = append(parts, m.styles.titleActive.Render(s.label))
+			continue
+		}
+		parts = append(parts, m.styles.muted.Render(s.label))
+	}
+	return strings.Join(parts, m.styles.muted.Render(" › "))
+}
+
+func (m model) inlineTitle(title, status string) string {
+	out := m.styles.titleActive.Render(title)
+	if strings.TrimSpace(status) != "" {
+		out += "  " + status
+	}
+	return out
+}
+
+// inlinePrompt renders a filter as an fzf-style prompt line.
+func (m model) inlinePrompt(label, value, placeholder string) string {
+	p := m.styles.key.Render(label + "> ")
+	if value == "" {
+		return p + m.styles.muted.Render(placeholder)
+	}
+	return p + value
+}
+
+// inlineList renders the current page of l, one row per item.
+func (m model) inlineList(l list.Model) []string {
+	items := l.Items()
+	start, end := l.Paginator.GetSliceBounds(len(items))
+	rows := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		rows = append(rows, m.inlineRow(items[i], i == l.Index()))
+	}
+	return rows
+}
+
+// inlineRow renders an item on one line: a cursor mark, the title with its
+// matched runes highlighted and as much of the description as fits.
+func (m model) inlineRow(it list.Item, selected bool) string {
+	w := m.inlineWidth() - 2
+	if h, ok := it.(groupHeader); ok {
+		return m.styles.muted.Render(ansi.Truncate("── "+h.Title()+" · "+h.Description(), w+2, "…"))
+	}
+	di, ok := it.(list.DefaultItem)
+	if !ok {
+		return ""
+	}
+	mark, base := "  ", m.styles.text
+	if selected {
+		mark, base = m.styles.key.Render("> "), m.styles.selected
+	}
+	title := ansi.Truncate(di.Title(), w, "…")
+	var matched []int
+	if tm, ok := it.(titleMatcher); ok {
+		matched = visibleRunes(tm.matchedRunes(), di.Title(), title)
+	}
+	row := mark + styleMatches(title, matched, base, m.styles.match)
+
+	room := w - ansi.StringWidth(title) - 2
+	if desc := di.Description(); desc != "" && room >= 8 {
+		shown := ansi.Truncate(desc, room, "…")
+		var descMatched []int
+		if dm, ok := it.(descMatcher); ok {
+			descMatched = visibleRunes(dm.matchedDescRunes(), desc, shown)
+		}
+		row += "  " + styleMatches(shown, descMatched, m.styles.muted, m.styles.match)
+	}
+	return row
+}
+
+func styleMatches(s string, idx []int, base, match lipgloss.Style) string {
+	if len(idx) == 0 {
+		return base.Render(s)
+	}
+	return lipgloss.StyleRunes(s, idx, base.Copy().Inherit(match), base)
+}
+
+// inlineFrame lays out the inline picker, padding the rows so the picker
+// keeps its height and cutting every line to the width.
+func (m model) inlineFrame(title, prompt string, rows []string, footer string) string {
+	lines := append([]string{title, prompt}, padLines(rows, m.inlineRows())...)
+	lines = append(lines, footer)
+	w := m.inlineWidth()
+	for i := range lines {
+		lines[i] = truncateANSI(lines[i], w)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// padLines cuts or pads lines to exactly n.
+func padLines(lines []string, n int) []string {
+	if len(lines) >= n {
+		return lines[:n]
+	}
+	return append(lines, make([]string, n-len(lines))...)
+}
+
+// clearInline erases the picker's last frame, which the renderer leaves on
+// screen when the program exits, so only the launched command follows the
+// prompt in the scrollback.
+func clearInline(w io.Writer, view string) {
+	if n := strings.Count(view, "\n"); n > 0 {
+		io.WriteString(w, ansi.CursorPreviousLine(n))
+	}
+	io.WriteString(w, "\r"+ansi.EraseDisplayRight)
+}
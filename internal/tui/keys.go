@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"oc/internal/config"
+)
+
+// keyMap holds the effective bindings for every rebindable action. Help keys
+// show the first bound key so help lines always match the keymap.
+type keyMap struct {
+	Quit          key.Binding
+	Launch        key.Binding
+	Back          key.Binding
+	Search        key.Binding
+	Recent        key.Binding
+	Projects      key.Binding
+	RevealHidden  key.Binding
+	Pin           key.Binding
+	Sort          key.Binding
+	EditNote      key.Binding
+	Help          key.Binding
+	Collapse      key.Binding
+	NextFocus     key.Binding
+	PrevFocus     key.Binding
+	SearchOrder   key.Binding
+	SearchMatches key.Binding
+	NextMatch     key.Binding
+	PrevMatch     key.Binding
+	SaveSearch    key.Binding
+}
+
+// newKeyMap builds the keymap from effective bindings keyed by action name
+// (see config.KeyActions). Missing actions keep their defaults.
+func newKeyMap(bindings map[string][]string) keyMap {
+	keys := func(action string) key.Binding {
+		ks, ok := bindings[action]
+		if !ok {
+			for _, a := range config.KeyActions {
+				if a.Name == action {
+					ks = a.Keys
+				}
+			}
+		}
+		if len(ks) == 0 {
+			// Unbound: never matches and drops out of help lines.
+			return key.NewBinding(key.WithDisabled())
+		}
+		return key.NewBinding(key.WithKeys(ks...), key.WithHelp(ks[0], action))
+	}
+	return keyMap{
+		Quit:          keys("quit"),
+		Launch:        keys("launch"),
+		Back:          keys("back"),
+		Search:        keys("search"),
+		Recent:        keys("recent"),
+		Projects:      keys("projects"),
+		RevealHidden:  keys("reveal_hidden"),
+		Pin:           keys("pin"),
+		Sort:          keys("sort"),
+		EditNote:      keys("edit_note"),
+		Help:          keys("help"),
+		Collapse:      keys("collapse_older"),
+		NextFocus:     keys("next_focus"),
+		PrevFocus:     keys("prev_focus"),
+		SearchOrder:   keys("search_order"),
+		SearchMatches: keys("search_matches"),
+		NextMatch:     keys("next_match"),
+		PrevMatch:     keys("prev_match"),
+		SaveSearch:    keys("save_search"),
+	}
+}
+
+// all lists every binding of the keymap.
+func (k keyMap) all() []key.Binding {
+	return []key.Binding{
+		k.Quit, k.Launch, k.Back, k.Search, k.Recent, k.Projects, k.RevealHidden,
+		k.Pin, k.Sort, k.EditNote, k.Help, k.Collapse, k.NextFocus, k.PrevFocus,
+		k.SearchOrder, k.SearchMatches, k.NextMatch, k.PrevMatch, k.SaveSearch,
+	}
+}
+
+// helpKey returns the key shown for b in help lines, or "" when b is unbound.
+func helpKey(b key.Binding) string {
+	if !b.Enabled() {
+		return ""
+	}
+	return b.Help().Key
+}
+
+// bind is a help line entry for b.
+func bind(b key.Binding, text string) helpBinding {
+	return helpBinding{key: helpKey(b), text: text}
+}
+
+// bindPair is a help line entry for two bindings shown as "a/b".
+func bindPair(a, b key.Binding, text string) helpBinding {
+	ka, kb := helpKey(a), helpKey(b)
+	switch {
+	case ka == "":
+		return helpBinding{key: kb, text: text}
+	case kb == "":
+		return helpBinding{key: ka, text: text}
+	}
+	return helpBinding{key: ka + "/" + kb, text: text}
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sahilm/fuzzy"
+)
+
+// matcher filters list items by the lowercased query typed above a column.
+type matcher struct {
+	// substring restores plain substring matching (ui.filter: substring);
+	// otherwise matching is fuzzy and ranked by score.
+	substring bool
+}
+
+// match matches q against fields, the first being the title shown in the
+// list. It returns the best score and the matched rune indexes of the title.
+func (mt matcher) match(q string, fields ...string) (score int, titleRunes []int, ok bool) {
+	if mt.substring {
+		for i, f := range fields {
+			if !strings.Contains(strings.ToLower(f), q) {
+				continue
+			}
+			ok = true
+			if i == 0 {
+				titleRunes = substringRunes(f, q)
+			}
+		}
+		return 0, titleRunes, ok
+	}
+	for _, fm := range fuzzy.FindNoSort(q, fields) {
+		if !ok || fm.Score > score {
+			score = fm.Score
+		}
+		ok = true
+		if fm.Index == 0 {
+			titleRunes = runeIndexes(fields[0], fm.MatchedIndexes)
+		}
+	}
+	return score, titleRunes, ok
+}
+
+// substringRunes returns the rune indexes of the first case-insensitive
+// occurrence of the lowercased q in s.
+func substringRunes(s, q string) []int {
+	lower := strings.ToLower(s)
+	i := strings.Index(lower, q)
+	if i < 0 || q == "" {
+		return nil
+	}
+	start := utf8.RuneCountInString(lower[:i])
+	out := make([]int, utf8.RuneCountInString(q))
+	for j := range out {
+		out[j] = start + j
+	}
+	return out
+}
+
+// runeIndexes converts the byte offsets fuzzy reports into rune indexes.
+func runeIndexes(s string, byteIdx []int) []int {
+	out := make([]int, 0, len(byteIdx))
+	r, b := 0, 0
+	for _, want := range byteIdx {
+		for b < want && b < len(s) {
+			_, size := utf8.DecodeRuneInString(s[b:])
+			b += size
+			r++
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// scoredItem is a list item with its match score, for ranking.
+type scoredItem struct {
+	item  list.Item
+	score int
+}
+
+// rankItems orders items by score, best first, keeping the incoming order
+// among ties.
+func rankItems(scored []scoredItem) []list.Item {
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+	out := make([]list.Item, len(scored))
+	for i, s := range scored {
+		out[i] = s.item
+	}
+	return out
+}
+
+// titleMatcher is implemented by items that know which title runes matched
+// the filter.
+type titleMatcher interface {
+	matchedRunes() []int
+}
+
+// descMatcher is implemented by items that also highlight description runes.
+type descMatcher interface {
+	matchedDescRunes() []int
+}
+
+// itemDelegate renders like list.DefaultDelegate and additionally highlights
+// the title and description runes that matched with Styles.FilterMatch.
+type itemDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	di, ok := item.(list.DefaultItem)
+	if !ok || m.Width() <= 0 {
+		return
+	}
+	s := &d.Styles
+	textW := m.Width() - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight()
+	title := ansi.Truncate(di.Title(), textW, "…")
+	desc := ansi.Truncate(di.Description(), textW, "…")
+
+	var matched, descMatched []int
+	if tm, ok := item.(titleMatcher); ok {
+		matched = visibleRunes(tm.matchedRunes(), di.Title(), title)
+	}
+	if dm, ok := item.(descMatcher); ok {
+		descMatched = visibleRunes(dm.matchedDescRunes(), di.Description(), desc)
+	}
+	titleStyle, descStyle := s.NormalTitle, s.NormalDesc
+	switch _, header := item.(groupHeader); {
+	case header:
+		titleStyle, descStyle = s.DimmedTitle, s.DimmedDesc
+	case index == m.Index():
+		titleStyle, descStyle = s.SelectedTitle, s.SelectedDesc
+	}
+	if len(matched) > 0 {
+		unmatched := titleStyle.Inline(true)
+		title = lipgloss.StyleRunes(title, matched, unmatched.Copy().Inherit(s.FilterMatch), unmatched)
+	}
+	title = titleStyle.Render(title)
+	if !d.ShowDescription {
+		io.WriteString(w, title)
+		return
+	}
+	if len(descMatched) > 0 {
+		unmatched := descStyle.Inline(true)
+		desc = lipgloss.StyleRunes(desc, descMatched, unmatched.Copy().Inherit(s.FilterMatch), unmatched)
+	}
+	io.WriteString(w, title+"\n"+descStyle.Render(desc))
+}
+
+// visibleRunes drops the indexes cut off when full was truncated to shown,
+// including the position of the ellipsis.
+func visibleRunes(idx []int, full, shown string) []int {
+	if shown == full {
+		return idx
+	}
+	n := len([]rune(shown)) - 1
+	out := idx[:0:0]
+	for _, i := range idx {
+		if i < n {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is how quickly a second click on the same item must
+// follow the first to launch it.
+const doubleClickInterval = 400 * time.Millisecond
+
+// itemRows is the height of one list item including the gap below it.
+var itemRows = func() int {
+	d := list.NewDefaultDelegate()
+	return d.Height() + d.Spacing()
+}()
+
+// listID names a list; the model is copied on every update, so pointers to
+// its lists can't be kept between messages.
+type listID int
+
+const (
+	listNone listID = iota
+	listProjects
+	listSessions
+	listModels
+	listRecent
+	listSearch
+)
+
+// lastClick remembers the previous click for double-click detection.
+type lastClick struct {
+	at    time.Time
+	list  listID
+	index int
+}
+
+func (m *model) listByID(id listID) *list.Model {
+	switch id {
+	case listProjects:
+		return &m.projList
+	case listSessions:
+		return &m.sesList
+	case listModels:
+		return &m.modelList
+	case listRecent:
+		return &m.recentList
+	case listSearch:
+		return &m.searchList
+	}
+	return nil
+}
+
+// panelTop is the screen row of the panels' top border: below the help line
+// and, in the wide layout, a blank line (narrow: the context line).
+func (m model) panelTop() int {
+	if m.viewMode != viewModeProjects || m.searchOpen {
+		if m.layoutMode() == layoutModeNarrow {
+			return 1
+		}
+	}
+	return 2
+}
+
+// columnAt returns the column under screen column x in the projects view.
+func (m model) columnAt(x int) (focus, bool) {
+	x -= outerMarginLeft
+	for _, c := range []struct {
+		f focus
+		w int
+	}{{focusProjects, m.colWProj}, {focusSessions, m.colWSes}, {focusModels, m.colWModel}} {
+		// Panels render two border cells wider than their width.
+		if x >= 0 && x < c.w+2 {
+			return c.f, true
+		}
+		x -= c.w + 2 + colGapSpaces
+	}
+	return 0, false
+}
+
+// listFor returns the list of a column and the screen row its items start at.
+func (m model) listFor(f focus) (listID, int) {
+	top := m.panelTop() + 2 // border and title
+	switch f {
+	case focusProjects:
+		return listProjects, top + 1 // filter line
+	case focusSessions:
+		return listSessions, top + 1
+	default:
+		return listModels, top + m.modelErrLines()
+	}
+}
+
+// itemAt returns the index of the item of l drawn at screen row y, where top
+// is the row of the first visible item.
+func itemAt(l *list.Model, top, y int) (int, bool) {
+	if y < top {
+		return 0, false
+	}
+	i := l.Paginator.Page*l.Paginator.PerPage + (y-top)/itemRows
+	end := minInt((l.Paginator.Page+1)*l.Paginator.PerPage, len(l.VisibleItems()))
+	if i >= end {
+		return 0, false
+	}
+	return i, true
+}
+
+// updateMouse focuses and selects on click, launches on double-click and
+// scrolls the list under the pointer with the wheel.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.editor.open || m.help.open {
+		return m, nil
+	}
+	if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+		k := tea.KeyMsg{Type: tea.KeyDown}
+		if msg.Button == tea.MouseButtonWheelUp {
+			k = tea.KeyMsg{Type: tea.KeyUp}
+		}
+		if m.searchOpen {
+			// Scroll the results; the wheel never recalls the search history.
+			return m.moveSearchList(k)
+		}
+		if m.viewMode == viewModeProjects {
+			f, ok := m.columnAt(msg.X)
+			if !ok || !m.focusColumn(f) {
+				return m, nil
+			}
+		}
+		return m.Update(k)
+	}
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+
+	var id listID
+	top := m.panelTop() + 2 // border and title
+	switch {
+	case m.searchOpen:
+		id, top = listSearch, top+1+lineCount(m.searchStatus()) // search line
+	case m.viewMode == viewModeRecentSessions:
+		id, top = listRecent, top+1+lineCount(m.recentStatus()) // filter line
+	default:
+		f, ok := m.columnAt(msg.X)
+		prev := m.focus
+		if !ok || !m.focusColumn(f) {
+			return m, nil
+		}
+		if f != prev && m.layoutMode() == layoutModeNarrow {
+			// Unfocused narrow columns only show their title.
+			return m, nil
+		}
+		id, top = m.listFor(f)
+	}
+	i, ok := itemAt(m.listByID(id), top, msg.Y)
+	if !ok {
+		return m, nil
+	}
+
+	double := m.click.list == id && m.click.index == i && time.Since(m.click.at) < doubleClickInterval
+	m.click = lastClick{at: time.Now(), list: id, index: i}
+	cmd := m.selectIndex(id, i)
+	if !double {
+		return m, cmd
+	}
+	m.click = lastClick{}
+	var launched bool
+	switch id {
+	case listSearch:
+		launched = m.launchSearchSelection()
+	case listRecent:
+		launched = m.launchRecentSelection()
+	default:
+		launched = m.setPlanFromSelection()
+	}
+	if launched {
+		return m, tea.Quit
+	}
+	return m, cmd
+}
+
+// focusColumn moves focus to f; a locked model column can't take focus.
+func (m *model) focusColumn(f focus) bool {
+	if f == focusModels && m.modelLocked() {
+		return false
+	}
+	if m.focus != f {
+		m.focus = f
+		m.updateFocus()
+	}
+	return true
+}
+
+// selectIndex selects item i of a list and applies the same follow-ups as
+// moving there with the keyboard.
+func (m *model) selectIndex(id listID, i int) tea.Cmd {
+	if _, ok := m.listByID(id).Items()[i].(groupHeader); ok {
+		return nil
+	}
+	switch id {
+	case listProjects:
+		oldID := m.selectedProjectID()
+		m.projList.Select(i)
+		return m.afterProjectMove(oldID)
+	case listSessions:
+		oldID := m.selectedSessionID()
+		m.sesList.Select(i)
+		m.afterSessionMove(oldID)
+		return nil
+	}
+	m.listByID(id).Select(i)
+	if id == listSearch {
+		m.syncSearchMatchIdx()
+	}
+	return nil
+}
+
+// lineCount is the number of lines s takes in a panel; empty takes none.
+func lineCount(s string) int {
+	if s == "" {
+		return 0
+	}
+	return 1
+}
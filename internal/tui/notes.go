@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"oc/internal/state"
+)
+
+// noteEditor edits the tags and note oc keeps for one session.
+type noteEditor struct {
+	open      bool
+	sessionID string
+	title     string
+	tags      textinput.Model
+	text      textinput.Model
+	onText    bool
+}
+
+func newNoteEditor(st styles) noteEditor {
+	tags := textinput.New()
+	tags.Prompt = "tags: "
+	tags.Placeholder = "ticket-123 spike"
+	tags.PlaceholderStyle = st.muted
+	tags.CharLimit = 200
+
+	text := textinput.New()
+	text.Prompt = "note: "
+	text.Placeholder = "one line"
+	text.PlaceholderStyle = st.muted
+	text.CharLimit = 200
+	return noteEditor{tags: tags, text: text}
+}
+
+// noteSuffix renders a note for item descriptions: "#tag #tag  note".
+func noteSuffix(n state.Note) string {
+	parts := make([]string, 0, len(n.Tags)+1)
+	for _, t := range n.Tags {
+		parts = append(parts, "#"+t)
+	}
+	if n.Text != "" {
+		parts = append(parts, n.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitTagQuery separates "#tag" terms from the rest of a filter query.
+func splitTagQuery(q string) (tags []string, rest string) {
+	var words []string
+	for _, f := range strings.Fields(q) {
+		if len(f) > 1 && strings.HasPrefix(f, "#") {
+			tags = append(tags, f)
+			continue
+		}
+		words = append(words, f)
+	}
+	return tags, strings.Join(words, " ")
+}
+
+// hasTags reports whether n has a tag starting with each of tags.
+func hasTags(n state.Note, tags []string) bool {
+	for _, t := range tags {
+		if !n.HasTagPrefix(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// openNoteEditor starts editing the note of a session.
+func (m *model) openNoteEditor(id, title string) {
+	if m.notes == nil || id == "" {
+		return
+	}
+	n := m.notes.Get(id)
+	m.editor.open = true
+	m.editor.sessionID = id
+	m.editor.title = title
+	m.editor.tags.SetValue(strings.Join(n.Tags, " "))
+	m.editor.text.SetValue(n.Text)
+	m.editor.onText = false
+	m.editor.tags.Focus()
+	m.editor.tags.CursorEnd()
+	m.editor.text.Blur()
+}
+
+func (m model) updateNoteEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.plan = nil
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.editor.open = false
+		return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		m.saveNote()
+		return m, nil
+	case key.Matches(msg, m.keys.NextFocus), key.Matches(msg, m.keys.PrevFocus), msg.Type == tea.KeyUp, msg.Type == tea.KeyDown:
+		m.editor.onText = !m.editor.onText
+		if m.editor.onText {
+			m.editor.tags.Blur()
+			m.editor.text.Focus()
+		} else {
+			m.editor.text.Blur()
+			m.editor.tags.Focus()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.editor.onText {
+		m.editor.text, cmd = m.editor.text.Update(msg)
+	} else {
+		m.editor.tags, cmd = m.editor.tags.Update(msg)
+	}
+	return m, cmd
+}
+
+// saveNote stores the edited note.
+func (m *model) saveNote() {
+	m.editor.open = false
+	m.setNote(m.editor.sessionID, state.Note{
+		Tags: state.ParseTags(m.editor.tags.Value()),
+		Text: m.editor.text.Value(),
+	})
+}
+
+// setNote replaces the note of a session, saves the notes right away and
+// refreshes the lists showing it.
+func (m *model) setNote(id string, n state.Note) {
+	m.notes.Set(id, n)
+	m.noteErr = ""
+	if err := m.notes.Save(); err != nil {
+		m.noteErr = err.Error()
+	}
+	m.refreshSession(id)
+}
+
+// refreshSession redraws a session with its current pin and note: the recent
+// sessions item in place, the sessions column by filtering again.
+func (m *model) refreshSession(id string) {
+	if m.viewMode == viewModeRecentSessions {
+		for i, it := range m.recentList.Items() {
+			if ri, ok := it.(recentSessionItem); ok && ri.res.Session.ID == id {
+				ri.note = m.notes.Get(id)
+				ri.pinned = m.pins.SessionPinned(id)
+				m.recentList.SetItem(i, ri)
+			}
+		}
+		return
+	}
+	m.applySessionFilter(false)
+}
+
+func (m model) noteEditorHelp() []helpBinding {
+	return []helpBinding{
+		bind(m.keys.Launch, "save"),
+		bind(m.keys.Back, "cancel"),
+		bindPair(m.keys.NextFocus, m.keys.PrevFocus, "tags/note"),
+		bind(m.keys.Quit, "quit"),
+	}
+}
+
+func (m model) viewNoteEditor() string {
+	header := m.helpLine(m.noteEditorHelp(), "")
+
+	fullW := maxInt(20, m.width-outerMarginLeft-outerMarginRight-m.safetySlack())
+	content := m.title("Tags & note", true) + "\n" +
+		m.styles.muted.Render(truncateANSI(m.editor.title, maxInt(10, fullW-4))) + "\n\n" +
+		m.editor.tags.View() + "\n" +
+		m.editor.text.View() + "\n\n" +
+		m.styles.muted.Render("Separate tags with spaces; filter sessions with #tag.")
+	panel := m.panelW(true, fullW, m.panelHeight, content)
+
+	return m.screen(header, panel)
+}
@@ -5,18 +5,38 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
 	"oc/internal/opencodestorage"
+	"oc/internal/state"
+)
+
+const (
+	// recentPageSize is how many recent sessions one page loads.
+	recentPageSize = 100
+	// recentPrefetch loads the next page once the selection is this close to
+	// the end of the list.
+	recentPrefetch = 10
 )
 
 type recentSessionItem struct {
-	res opencodestorage.SessionSearchResult
+	res     opencodestorage.SessionSearchResult
+	pinned  bool
+	note    state.Note
+	matched []int
 }
 
+func (it recentSessionItem) matchedRunes() []int { return it.matched }
+
 func (it recentSessionItem) Title() string {
 	t := strings.TrimSpace(it.res.Session.Title)
 	if t == "" {
 		t = "(untitled)"
 	}
+	if it.pinned {
+		return pinMarker + t
+	}
 	return t
 }
 
@@ -25,7 +45,7 @@ func (it recentSessionItem) Description() string {
 	proj := shortenPath(it.res.ProjectWorktree, 60)
 	dir := shortenPath(it.res.Session.Directory, 60)
 
-	parts := make([]string, 0, 3)
+	parts := make([]string, 0, 4)
 	if updated != "" {
 		parts = append(parts, updated)
 	}
@@ -35,11 +55,51 @@ func (it recentSessionItem) Description() string {
 	if dir != "" && dir != proj {
 		parts = append(parts, dir)
 	}
+	if note := noteSuffix(it.note); note != "" {
+		parts = append(parts, note)
+	}
 	return strings.Join(parts, "  ")
 }
 
 func (it recentSessionItem) FilterValue() string { return it.Title() + " " + it.Description() }
 
+// loadMoreRecentCmd loads the next page of recent sessions when the
+// selection nears the end of the list, or nil when no page is due. It runs
+// when the user scrolls and after a page that added visible rows.
+func (m *model) loadMoreRecentCmd() tea.Cmd {
+	if !m.recentMore || m.recentLoading || m.viewMode != viewModeRecentSessions {
+		return nil
+	}
+	if len(m.recentList.Items())-m.recentList.Index() > recentPrefetch {
+		return nil
+	}
+	m.recentLoading = true
+	return m.loadRecentSessionsCmd()
+}
+
+// groupByProject puts recent sessions under a header per project, projects
+// ordered by their newest session.
+func (m model) groupByProject(items []list.Item) []list.Item {
+	var order []string
+	byProject := map[string][]list.Item{}
+	for _, it := range items {
+		id := it.(recentSessionItem).res.ProjectID
+		if _, ok := byProject[id]; !ok {
+			order = append(order, id)
+		}
+		byProject[id] = append(byProject[id], it)
+	}
+	out := make([]list.Item, 0, len(items)+len(order))
+	for _, id := range order {
+		group := byProject[id]
+		r := group[0].(recentSessionItem).res
+		p := projectItem{Project: opencodestorage.Project{ID: id, Worktree: r.ProjectWorktree}, display: m.projectDisplay[id]}
+		out = append(out, groupHeader{label: p.Title(), count: len(group)})
+		out = append(out, group...)
+	}
+	return out
+}
+
 func formatUpdatedRelative(ms int64) string {
 	if ms <= 0 {
 		return ""
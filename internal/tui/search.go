@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"oc/internal/opencodestorage"
 )
 
@@ -41,10 +44,13 @@ func (it sessionSearchItem) Description() string {
 	dir := shortenPath(it.res.Session.Directory, 40)
 	snippet := excerptMatch(it.res.MatchText, it.queryLC, 90)
 
-	parts := make([]string, 0, 4)
+	parts := make([]string, 0, 5)
 	if updated != "" {
 		parts = append(parts, updated)
 	}
+	if n := it.res.MatchCount; n > 0 {
+		parts = append(parts, formatMatchCount(n))
+	}
 	if proj != "" {
 		parts = append(parts, proj)
 	}
@@ -59,6 +65,151 @@ func (it sessionSearchItem) Description() string {
 
 func (it sessionSearchItem) FilterValue() string { return it.Title() + " " + it.Description() }
 
+// matchedRunes and matchedDescRunes mark every occurrence of a query term for
+// the delegate to highlight.
+func (it sessionSearchItem) matchedRunes() []int {
+	return queryRunes(it.Title(), opencodestorage.SearchTerms(it.queryLC))
+}
+
+func (it sessionSearchItem) matchedDescRunes() []int {
+	return queryRunes(it.Description(), opencodestorage.SearchTerms(it.queryLC))
+}
+
+// queryRunes returns the rune indexes of s covered by a case-insensitive
+// occurrence of any of the lowercase terms.
+func queryRunes(s string, terms []string) []int {
+	text := lowerRunes(s)
+	hit := make([]bool, len(text))
+	for _, t := range terms {
+		tr := []rune(t)
+		for i := 0; len(tr) > 0 && i+len(tr) <= len(text); i++ {
+			if runesEqual(text[i:i+len(tr)], tr) {
+				for j := range tr {
+					hit[i+j] = true
+				}
+			}
+		}
+	}
+	var out []int
+	for i, h := range hit {
+		if h {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// lowerRunes lowercases s rune by rune, so indexes match []rune(s).
+func lowerRunes(s string) []rune {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return r
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// indexRunes returns the rune index of the first occurrence of sub in text,
+// or -1.
+func indexRunes(text, sub []rune) int {
+	for i := 0; len(sub) > 0 && i+len(sub) <= len(text); i++ {
+		if runesEqual(text[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatMatchCount(n int) string {
+	if n == 1 {
+		return "1 match"
+	}
+	return fmt.Sprintf("%d matches", n)
+}
+
+// searchMatchesHeight is the number of lines the expanded match view takes
+// below the results list (separator + header + snippet lines).
+const searchMatchesHeight = 5
+
+// selectedSearchResult returns the highlighted search result, if any.
+func (m model) selectedSearchResult() (opencodestorage.SessionSearchResult, bool) {
+	it := m.searchList.SelectedItem()
+	if it == nil {
+		return opencodestorage.SessionSearchResult{}, false
+	}
+	si, ok := it.(sessionSearchItem)
+	if !ok {
+		return opencodestorage.SessionSearchResult{}, false
+	}
+	return si.res, true
+}
+
+// stepSearchMatch moves through the snippets of the selected result.
+func (m *model) stepSearchMatch(delta int) {
+	res, ok := m.selectedSearchResult()
+	if !ok || len(res.Matches) == 0 {
+		return
+	}
+	m.syncSearchMatchIdx()
+	n := len(res.Matches)
+	m.searchMatchIdx = (m.searchMatchIdx + delta + n) % n
+}
+
+// syncSearchMatchIdx resets the snippet cursor when the selection changed.
+func (m *model) syncSearchMatchIdx() {
+	res, ok := m.selectedSearchResult()
+	if !ok {
+		m.searchMatchID = ""
+		m.searchMatchIdx = 0
+		return
+	}
+	if res.Session.ID != m.searchMatchID {
+		m.searchMatchID = res.Session.ID
+		m.searchMatchIdx = 0
+	}
+}
+
+func (m model) viewSearchMatches(width int) string {
+	res, ok := m.selectedSearchResult()
+	if !ok || len(res.Matches) == 0 {
+		return m.styles.muted.Render("no snippets")
+	}
+	idx := 0
+	if res.Session.ID == m.searchMatchID {
+		idx = clampInt(m.searchMatchIdx, 0, len(res.Matches)-1)
+	}
+	match := res.Matches[idx]
+
+	header := fmt.Sprintf("match %d/%d", idx+1, len(res.Matches))
+	if res.MatchCount > len(res.Matches) {
+		header += fmt.Sprintf(" (newest of %d)", res.MatchCount)
+	}
+	if ts := formatUpdated(match.Created); ts != "" {
+		header += "  " + ts
+	}
+
+	textLines := searchMatchesHeight - 2
+	q := strings.ToLower(strings.TrimSpace(m.searchInput.Value()))
+	snippet := excerptMatch(match.Text, q, maxInt(20, width*textLines))
+	if hits := queryRunes(snippet, opencodestorage.SearchTerms(q)); len(hits) > 0 {
+		snippet = lipgloss.StyleRunes(snippet, hits, m.styles.match, lipgloss.NewStyle())
+	}
+	body := lipgloss.NewStyle().Width(width).MaxHeight(textLines).Render(snippet)
+	return m.styles.muted.Render(strings.Repeat("─", maxInt(0, width))) + "\n" +
+		m.styles.muted.Render(header) + "\n" + body
+}
+
 func excerptMatch(text string, queryLower string, maxLen int) string {
 	text = strings.TrimSpace(text)
 	if text == "" {
@@ -77,11 +228,20 @@ func excerptMatch(text string, queryLower string, maxLen int) string {
 	}, text)
 	text = strings.Join(strings.Fields(text), " ")
 
-	q := strings.TrimSpace(queryLower)
-	if q == "" {
-		return truncatePlain(text, maxLen)
+	// Center on the query, or else on the first term found; work in runes so
+	// multi-byte text is never split.
+	runes := []rune(text)
+	lower := lowerRunes(text)
+	q := []rune(strings.TrimSpace(queryLower))
+	idx := indexRunes(lower, q)
+	if idx < 0 {
+		for _, t := range opencodestorage.SearchTerms(string(q)) {
+			tr := []rune(t)
+			if i := indexRunes(lower, tr); i >= 0 && (idx < 0 || i < idx) {
+				idx, q = i, tr
+			}
+		}
 	}
-	idx := strings.Index(strings.ToLower(text), q)
 	if idx < 0 {
 		return truncatePlain(text, maxLen)
 	}
@@ -96,28 +256,30 @@ func excerptMatch(text string, queryLower string, maxLen int) string {
 		start = 0
 	}
 	end := idx + len(q) + after
-	if end > len(text) {
-		end = len(text)
+	if end > len(runes) {
+		end = len(runes)
 	}
-	chunk := text[start:end]
+	chunk := string(runes[start:end])
 	if start > 0 {
 		chunk = "..." + strings.TrimLeft(chunk, " ")
 	}
-	if end < len(text) {
+	if end < len(runes) {
 		chunk = strings.TrimRight(chunk, " ") + "..."
 	}
 	return truncatePlain(chunk, maxLen)
 }
 
+// truncatePlain shortens s to maxLen runes, ending in "..." when cut.
 func truncatePlain(s string, maxLen int) string {
 	if maxLen <= 0 {
 		return ""
 	}
-	if len(s) <= maxLen {
+	r := []rune(s)
+	if len(r) <= maxLen {
 		return s
 	}
 	if maxLen < 4 {
-		return s[:maxLen]
+		return string(r[:maxLen])
 	}
-	return s[:maxLen-3] + "..."
+	return string(r[:maxLen-3]) + "..."
 }
@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"oc/internal/state"
+)
+
+// savedSearchItem is a saved search listed in the search view while the
+// search box is empty.
+type savedSearchItem struct{ s state.SavedSearch }
+
+func (it savedSearchItem) Title() string       { return it.s.Name }
+func (it savedSearchItem) Description() string { return it.s.Query }
+func (it savedSearchItem) FilterValue() string { return it.s.Name + " " + it.s.Query }
+
+func newSearchNameInput(st styles) textinput.Model {
+	name := textinput.New()
+	name.Prompt = "save as: "
+	name.Placeholder = "name"
+	name.PlaceholderStyle = st.muted
+	name.CharLimit = 60
+	return name
+}
+
+// recallSearch steps through the search history: delta 1 is an older query,
+// -1 a newer one. It only takes over up/down while the box is empty or still
+// shows a recalled query, so editing a query gives the keys back to the
+// results. Once results are listed, up/down move through them; only up on the
+// first result, before any navigation, recalls an older query.
+func (m model) recallSearch(delta int) (model, tea.Cmd, bool) {
+	if len(m.searchList.Items()) > 0 && (delta < 0 || m.searchMoved || m.searchList.Index() > 0) {
+		return m, nil, false
+	}
+	hist := m.searches.Recent()
+	cur := strings.TrimSpace(m.searchInput.Value())
+	recalling := m.searchHist >= 0 && m.searchHist < len(hist) && hist[m.searchHist] == cur
+	if !recalling && (cur != "" || delta < 0) {
+		return m, nil, false
+	}
+	if !recalling {
+		m.searchHist = -1
+	}
+	next := m.searchHist + delta
+	if next >= len(hist) {
+		return m, nil, len(hist) > 0
+	}
+	m.searchHist = next
+	q := ""
+	if next >= 0 {
+		q = hist[next]
+	}
+	m, cmd := m.runSearchQuery(q)
+	return m, cmd, true
+}
+
+// moveSearchList sends a navigation key to the search results.
+func (m model) moveSearchList(msg tea.KeyMsg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.searchList, cmd = m.searchList.Update(msg)
+	m.searchMoved = true
+	return m, cmd
+}
+
+// runSearchQuery puts q in the search box and searches for it right away.
+func (m model) runSearchQuery(q string) (model, tea.Cmd) {
+	m.searchInput.SetValue(q)
+	m.searchInput.CursorEnd()
+	m.searchSeq++
+	return m.startSearch(q)
+}
+
+// rememberSearch records the current query in the search history.
+func (m *model) rememberSearch() {
+	q := strings.TrimSpace(m.searchInput.Value())
+	if m.searches == nil || q == "" {
+		return
+	}
+	m.searches.AddHistory(q, m.searchHistoryMax)
+	m.saveSearches()
+}
+
+// openSavedSearches lists the saved searches in place of the results.
+func (m *model) openSavedSearches() {
+	saved := m.searches.SavedSearches()
+	if len(saved) == 0 {
+		m.searchNote = "no saved searches yet: type a query and press " + helpKey(m.keys.SaveSearch)
+		return
+	}
+	items := make([]list.Item, 0, len(saved))
+	for _, s := range saved {
+		items = append(items, savedSearchItem{s})
+	}
+	m.searchPicking = true
+	m.searchList.SetItems(items)
+	m.searchList.Select(0)
+}
+
+func (m *model) closeSavedSearches() {
+	m.searchPicking = false
+	m.searchList.SetItems(nil)
+	m.searchList.Select(0)
+}
+
+func (m model) updateSavedSearches(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.plan = nil
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.SaveSearch):
+		m.closeSavedSearches()
+		return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		it, ok := m.searchList.SelectedItem().(savedSearchItem)
+		m.closeSavedSearches()
+		if !ok {
+			return m, nil
+		}
+		m.searchHist = -1
+		return m.runSearchQuery(it.s.Query)
+	case msg.Type == tea.KeyDelete:
+		if it, ok := m.searchList.SelectedItem().(savedSearchItem); ok {
+			m.searches.DeleteSaved(it.s.Name)
+			m.saveSearches()
+			i := m.searchList.Index()
+			m.closeSavedSearches()
+			m.openSavedSearches()
+			if m.searchPicking {
+				m.searchList.Select(minInt(i, len(m.searchList.Items())-1))
+			}
+		}
+		return m, nil
+	case isNavKey(msg):
+		var cmd tea.Cmd
+		m.searchList, cmd = m.searchList.Update(msg)
+		return m, cmd
+	}
+	// Typing starts a new query.
+	m.closeSavedSearches()
+	return m.updateSearch(msg)
+}
+
+// openSearchName prompts for the name to save the current query under.
+func (m *model) openSearchName() {
+	m.searchNaming = true
+	m.searchName.SetValue("")
+	m.searchName.Focus()
+	m.searchInput.Blur()
+}
+
+func (m *model) closeSearchName() {
+	m.searchNaming = false
+	m.searchName.Blur()
+	m.searchInput.Focus()
+}
+
+func (m model) updateSearchName(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.plan = nil
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.closeSearchName()
+		return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		name := strings.TrimSpace(m.searchName.Value())
+		if name == "" {
+			return m, nil
+		}
+		m.closeSearchName()
+		if m.searches.SaveSearch(name, m.searchInput.Value()) && m.saveSearches() {
+			m.searchNote = "saved as " + name
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.searchName, cmd = m.searchName.Update(msg)
+	return m, cmd
+}
+
+// saveSearches writes the searches and reports whether that worked; the error
+// shows in the search view.
+func (m *model) saveSearches() bool {
+	m.searchesErr = ""
+	if err := m.searches.Save(); err != nil {
+		m.searchesErr = err.Error()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"oc/internal/opencodestorage"
+)
+
+// refreshSources takes a snapshot of the store's sources after a read, so the
+// status bar shows the counts and errors as of now.
+func (m *model) refreshSources(readAt time.Time) {
+	m.sources = opencodestorage.SourcesOf(m.store)
+	m.readAt = readAt
+}
+
+// statusBar is the bottom line of the full-screen views: the sources behind
+// the picker with their paths and counts, when data was last read and any
+// degraded source. It drops detail, paths first, until it fits width.
+func (m model) statusBar(width int) string {
+	sep := m.styles.muted.Render(" · ")
+	var line string
+	for detail := statusFull; detail <= statusMinimal; detail++ {
+		line = strings.Join(m.statusParts(detail), sep)
+		if ansi.StringWidth(line) <= width {
+			return line
+		}
+	}
+	return truncateANSI(line, width)
+}
+
+// Status bar detail levels, from the most to the least.
+const (
+	statusFull    = iota // paths, counts, read time and warning messages
+	statusNoPaths        // counts, read time and warning messages
+	statusCompact        // counts, read time and degraded source names
+	statusMinimal        // source names and degraded source names
+)
+
+func (m model) statusParts(detail int) []string {
+	var parts []string
+	if len(m.sources) == 0 {
+		// The store doesn't describe its sources; count what the picker has.
+		if detail < statusMinimal {
+			parts = append(parts, m.styles.muted.Render(countLabel(len(m.projectsAll), "project")+", "+countLabel(m.sessionsLoaded(), "session")))
+		}
+	} else if detail == statusMinimal {
+		names := make([]string, 0, len(m.sources))
+		for _, src := range m.sources {
+			names = append(names, src.Name)
+		}
+		parts = append(parts, m.styles.muted.Render(strings.Join(names, "+")))
+	} else {
+		for _, src := range m.sources {
+			if detail > statusFull && src.Err != nil && src.Projects == 0 {
+				// Its warning already names it.
+				continue
+			}
+			parts = append(parts, m.styles.muted.Render(m.sourceLabel(src, detail == statusFull)))
+		}
+	}
+	if detail < statusMinimal && !m.readAt.IsZero() {
+		parts = append(parts, m.styles.muted.Render("read "+m.readAt.Format("15:04:05")))
+	}
+	for _, src := range m.sources {
+		if src.Err == nil {
+			continue
+		}
+		warn := "⚠ " + src.Name
+		if detail < statusCompact {
+			warn += ": " + src.Err.Error()
+		}
+		parts = append(parts, m.styles.titleActive.Render(warn))
+	}
+	return parts
+}
+
+func (m model) sourceLabel(src opencodestorage.SourceStatus, withPath bool) string {
+	label := src.Name
+	if withPath && src.Path != "" {
+		label += " " + shortenPath(src.Path, 60)
+	}
+	if src.Err != nil && src.Projects == 0 {
+		return label + ": unavailable"
+	}
+	return label + ": " + countLabel(src.Projects, "project") + ", " + countLabel(src.Sessions, "session")
+}
+
+// sessionsLoaded counts the sessions loaded across all projects so far.
+func (m model) sessionsLoaded() int {
+	n := 0
+	for _, sessions := range m.sessionsByProject {
+		n += len(sessions)
+	}
+	return n
+}
+
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
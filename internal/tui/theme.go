@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"oc/internal/config"
+)
+
+// theme assigns a color to every style role (see config.ThemeRoles).
+type theme struct {
+	noColor bool
+
+	accent              lipgloss.TerminalColor
+	title               lipgloss.TerminalColor
+	muted               lipgloss.TerminalColor
+	border              lipgloss.TerminalColor
+	key                 lipgloss.TerminalColor
+	text                lipgloss.TerminalColor
+	description         lipgloss.TerminalColor
+	selected            lipgloss.TerminalColor
+	selectedDescription lipgloss.TerminalColor
+}
+
+var builtinThemes = map[string]theme{
+	"dark": {
+		accent:              lipgloss.Color("205"),
+		title:               lipgloss.Color("245"),
+		muted:               lipgloss.Color("245"),
+		border:              lipgloss.Color("238"),
+		key:                 lipgloss.Color("#EE6FF8"),
+		text:                lipgloss.Color("#dddddd"),
+		description:         lipgloss.Color("#777777"),
+		selected:            lipgloss.Color("#EE6FF8"),
+		selectedDescription: lipgloss.Color("#AD58B4"),
+	},
+	"light": {
+		accent:              lipgloss.Color("162"),
+		title:               lipgloss.Color("240"),
+		muted:               lipgloss.Color("242"),
+		border:              lipgloss.Color("250"),
+		key:                 lipgloss.Color("#B5179E"),
+		text:                lipgloss.Color("#1a1a1a"),
+		description:         lipgloss.Color("#6B6570"),
+		selected:            lipgloss.Color("#B5179E"),
+		selectedDescription: lipgloss.Color("#8E3B86"),
+	},
+	"high-contrast": {
+		accent:              lipgloss.Color("11"),
+		title:               lipgloss.Color("15"),
+		muted:               lipgloss.Color("7"),
+		border:              lipgloss.Color("15"),
+		key:                 lipgloss.Color("11"),
+		text:                lipgloss.Color("15"),
+		description:         lipgloss.Color("7"),
+		selected:            lipgloss.Color("11"),
+		selectedDescription: lipgloss.Color("14"),
+	},
+}
+
+// detectThemeName resolves "auto" from the terminal background.
+func detectThemeName() string {
+	if termenv.HasDarkBackground() {
+		return "dark"
+	}
+	return "light"
+}
+
+// newTheme builds the theme for cfg. An empty or "auto" name falls back to
+// dark; Run resolves "auto" before calling this. noColor drops every color.
+func newTheme(cfg config.Theme, noColor bool) theme {
+	if noColor {
+		none := lipgloss.NoColor{}
+		return theme{
+			noColor: true,
+			accent:  none, title: none, muted: none, border: none, key: none,
+			text: none, description: none, selected: none, selectedDescription: none,
+		}
+	}
+	th, ok := builtinThemes[strings.TrimSpace(cfg.Name)]
+	if !ok {
+		th = builtinThemes["dark"]
+	}
+	for role, c := range cfg.Roles {
+		color := lipgloss.Color(strings.TrimSpace(c))
+		switch role {
+		case "accent":
+			th.accent = color
+		case "title":
+			th.title = color
+		case "muted":
+			th.muted = color
+		case "border":
+			th.border = color
+		case "key":
+			th.key = color
+		case "text":
+			th.text = color
+		case "description":
+			th.description = color
+		case "selected":
+			th.selected = color
+		case "selected_description":
+			th.selectedDescription = color
+		}
+	}
+	return th
+}
+
+func newStyles(th theme) styles {
+	st := styles{
+		titleActive: lipgloss.NewStyle().Bold(true).Foreground(th.accent),
+		titleIdle:   lipgloss.NewStyle().Bold(true).Foreground(th.title),
+		panel:       lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(th.border).Padding(0, 1),
+		panelActive: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(th.accent).Padding(0, 1),
+		muted:       lipgloss.NewStyle().Foreground(th.muted),
+		key:         lipgloss.NewStyle().Foreground(th.key),
+		match:       lipgloss.NewStyle().Bold(true).Foreground(th.accent),
+		text:        lipgloss.NewStyle().Foreground(th.text),
+		selected:    lipgloss.NewStyle().Bold(true).Foreground(th.selected),
+	}
+	if th.noColor {
+		// Without color, focus is shown by shape: a thick border and an
+		// underlined title.
+		st.titleActive = st.titleActive.Underline(true)
+		st.titleIdle = st.titleIdle.Bold(false)
+		st.panelActive = st.panelActive.Border(lipgloss.ThickBorder())
+	}
+	return st
+}
+
+// newDelegate returns the list delegate colored by th.
+func newDelegate(th theme) itemDelegate {
+	d := list.NewDefaultDelegate()
+	s := &d.Styles
+	s.NormalTitle = s.NormalTitle.Foreground(th.text)
+	s.NormalDesc = s.NormalDesc.Foreground(th.description)
+	s.SelectedTitle = s.SelectedTitle.Foreground(th.selected).BorderForeground(th.selected)
+	s.SelectedDesc = s.SelectedDesc.Foreground(th.selectedDescription).BorderForeground(th.selected)
+	s.DimmedTitle = s.DimmedTitle.Foreground(th.muted)
+	s.DimmedDesc = s.DimmedDesc.Foreground(th.muted)
+	s.FilterMatch = lipgloss.NewStyle().Underline(true)
+	if th.noColor {
+		s.SelectedTitle = s.SelectedTitle.Bold(true)
+	}
+	return itemDelegate{d}
+}
+
+// newSearchDelegate is newDelegate with query matches in bold accent color
+// instead of underlined, so they stand out in long result rows.
+func newSearchDelegate(th theme) itemDelegate {
+	d := newDelegate(th)
+	d.Styles.FilterMatch = newStyles(th).match
+	return d
+}
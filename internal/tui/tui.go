@@ -10,14 +10,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 
 	"oc/internal/config"
 	"oc/internal/opencodestorage"
+	"oc/internal/state"
 )
 
 var searchStages = []int{50, 200, 1000, 0}
@@ -36,24 +39,89 @@ type Input struct {
 	DefaultModel             config.Model
 	HideGlobalProjects       bool
 	GlobalSessionsMaxAgeDays int
+	// ProjectModels resolves per-project model rules; nil means every project
+	// uses Models and DefaultModel. A non-nil error is shown in the model
+	// column while the returned models are still used.
+	ProjectModels func(worktree string) (config.ProjectModels, error)
+	// Theme selects the color theme; Run resolves "auto" from the terminal
+	// background.
+	Theme config.Theme
+	// NoColor disables all colors (NO_COLOR or --no-color).
+	NoColor bool
+	// ProjectDisplay resolves display names, aliases, icons, tags and hide
+	// rules per worktree; nil shows every project by its base name.
+	ProjectDisplay func(worktree string) config.ProjectDisplay
+	// Keys maps action names (see config.KeyActions) to keys; nil uses the
+	// defaults.
+	Keys map[string][]string
+	// SessionLimits resolves the session policy per worktree; nil shows every
+	// session.
+	SessionLimits func(worktree string) config.SessionLimits
+	// RecentLimits is the session policy of the recent sessions view.
+	RecentLimits config.SessionLimits
+	// RecentByProject groups the recent sessions by project instead of date.
+	RecentByProject bool
+	// Pins holds pinned projects and sessions; nil disables pinning.
+	Pins *state.Pins
+	// Notes holds oc's session tags and notes; nil disables editing them.
+	Notes *state.Notes
+	// Launches is the launch history behind the frecency sort.
+	Launches []state.Launch
+	// Sort picks the initial project and model order.
+	Sort config.Sort
+	// SubstringFilter filters lists by substring instead of fuzzy matching.
+	SubstringFilter bool
+	// Mouse enables clicking, double-clicking and wheel scrolling.
+	Mouse bool
+	// Searches holds the search history and saved searches; nil disables
+	// both.
+	Searches *state.Searches
+	// SearchHistory caps how many recent queries are kept.
+	SearchHistory int
+	// SearchQuery opens global search with this query already running.
+	SearchQuery string
+	// Vim enables modal navigation: j/k/g/G/h/l move, / filters and :
+	// runs commands.
+	Vim bool
+	// Inline renders a compact picker of InlineHeight lines below the
+	// prompt instead of taking over the screen. It has no mouse support.
+	Inline       bool
+	InlineHeight int
+	// LoadedAt is when the projects were read, shown in the status bar; zero
+	// means when the picker starts.
+	LoadedAt time.Time
 }
 
 type LaunchPlan struct {
 	ProjectDir string
 	Model      config.Model
-	SessionID  string // empty means new session
+	SessionID  string          // empty means new session
+	Profile    *config.Profile // nil means launch with Model only
 }
 
 func Run(in Input) (*LaunchPlan, error) {
+	if in.NoColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else if name := strings.TrimSpace(in.Theme.Name); name == "" || name == "auto" {
+		in.Theme.Name = detectThemeName()
+	}
 	m := newModel(in)
-	// Enable mouse reporting so the terminal doesn't scroll the alternate screen.
-	// We ignore all mouse events in Update.
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	// Mouse reporting stays on even without ui.mouse so the terminal doesn't
+	// scroll the alternate screen; Update then ignores mouse events.
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if in.Inline {
+		// Inline draws below the prompt and leaves the mouse to the terminal.
+		opts = nil
+	}
+	p := tea.NewProgram(m, opts...)
 	res, err := p.Run()
 	if err != nil {
 		return nil, err
 	}
 	final := res.(model)
+	if in.Inline {
+		clearInline(os.Stdout, final.View())
+	}
 	return final.plan, nil
 }
 
@@ -79,7 +147,9 @@ type sessionsLoadedMsg struct {
 }
 
 type recentSessionsLoadedMsg struct {
+	seq     int
 	results []opencodestorage.SessionSearchResult
+	more    bool
 	err     error
 }
 
@@ -87,15 +157,46 @@ type model struct {
 	store                    opencodestorage.Store
 	hideGlobalProjects       bool
 	globalSessionsMaxAgeDays int
+	sessionLimits            func(worktree string) config.SessionLimits
+	recentLimits             config.SessionLimits
+	// revealHidden shows the sessions hidden by the session policy.
+	revealHidden bool
+	pins         *state.Pins
+	pinErr       string
+	// collapseOlder folds the older date groups of the session lists.
+	collapseOlder bool
+	notes         *state.Notes
+	noteErr       string
+	editor        noteEditor
+	help          helpOverlay
+
+	// Frecency scores by worktree and by model ID, and whether the project
+	// and model columns are sorted by them.
+	projectScores   map[string]float64
+	modelScores     map[string]float64
+	frecentProjects bool
+	frecentModels   bool
 
 	viewMode viewMode
 
 	projectsAll       []opencodestorage.Project
+	projectDisplay    map[string]config.ProjectDisplay
 	sessionsByProject map[string][]opencodestorage.Session
 	loadingSessions   map[string]bool
+	sesHidden         int
+
+	// sources and readAt back the status bar: the store's sources as of the
+	// last read, and when that was.
+	sources []opencodestorage.SourceStatus
+	readAt  time.Time
 
 	models          []config.Model
 	defaultModelIdx int
+	projectModels   func(worktree string) (config.ProjectModels, error)
+	keys            keyMap
+	projectCache    map[string]projectModelsResult
+	shownModels     config.ProjectModels
+	modelErr        string
 
 	plan *LaunchPlan
 
@@ -109,10 +210,25 @@ type model struct {
 	colWSes     int
 	colWModel   int
 
-	projFilter    textinput.Model
-	sesFilter     textinput.Model
-	lastProjQuery string
-	lastSesQuery  string
+	projFilter textinput.Model
+	sesFilter  textinput.Model
+	matcher    matcher
+
+	mouse bool
+	click lastClick
+
+	// Vim mode: vimInsert is set while typing into a filter or the search
+	// box, cmdOpen while the ":" command line is open. cmdNote is the result
+	// of the last command.
+	vim       bool
+	vimInsert bool
+	cmdOpen   bool
+	cmdline   textinput.Model
+	cmdNote   string
+
+	// inline renders the compact picker of inlineLines lines.
+	inline      bool
+	inlineLines int
 
 	searchOpen      bool
 	searchPrevFocus focus
@@ -129,10 +245,37 @@ type model struct {
 	searchSpinIdx   int
 	searchSpinning  bool
 	searchCancel    context.CancelFunc
+	searchExpanded  bool
+	searchMatchID   string
+	searchMatchIdx  int
+	searchOrder     opencodestorage.SearchOrder
+	// Search history and saved searches: searchHist is the recalled history
+	// entry (-1 for none), searchMoved records that the results were
+	// navigated since they arrived, searchPicking lists the saved searches in
+	// place of the results and searchNaming prompts for a name to save under.
+	searches         *state.Searches
+	searchHistoryMax int
+	searchHist       int
+	searchMoved      bool
+	searchesErr      string
+	searchNote       string
+	searchPicking    bool
+	searchNaming     bool
+	searchName       textinput.Model
 
 	recentList    list.Model
+	recentFilter  textinput.Model
+	recentAll     []opencodestorage.SessionSearchResult
+	recentHidden  int
 	recentLoading bool
 	recentErr     string
+	// Pagination: recentSeq drops pages of an earlier opening of the view,
+	// recentCursor is where the next page starts and recentMore tells whether
+	// there may be one.
+	recentSeq       int
+	recentCursor    opencodestorage.RecentCursor
+	recentMore      bool
+	recentByProject bool
 
 	projList  list.Model
 	modelList list.Model
@@ -170,63 +313,74 @@ type styles struct {
 	panel       lipgloss.Style
 	panelActive lipgloss.Style
 	muted       lipgloss.Style
+	key         lipgloss.Style
+	// match highlights search query occurrences.
+	match lipgloss.Style
+	// text and selected are list rows of the inline picker.
+	text     lipgloss.Style
+	selected lipgloss.Style
 }
 
 func newModel(in Input) model {
-	st := styles{
-		titleActive: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")),
-		titleIdle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245")),
-		panel:       lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("238")).Padding(0, 1),
-		panelActive: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("205")).Padding(0, 1),
-		muted:       lipgloss.NewStyle().Foreground(lipgloss.Color("245")),
-	}
+	th := newTheme(in.Theme, in.NoColor)
+	st := newStyles(th)
 
 	projFilter := textinput.New()
 	projFilter.Placeholder = "type to filter"
 	projFilter.Prompt = ""
+	projFilter.PlaceholderStyle = st.muted
 	projFilter.CharLimit = 100
 	projFilter.Focus()
 
 	sesFilter := textinput.New()
 	sesFilter.Placeholder = "type to filter"
 	sesFilter.Prompt = ""
+	sesFilter.PlaceholderStyle = st.muted
 	sesFilter.CharLimit = 100
 	sesFilter.Focus()
 
+	recentFilter := textinput.New()
+	recentFilter.Placeholder = "type to filter"
+	recentFilter.Prompt = ""
+	recentFilter.PlaceholderStyle = st.muted
+	recentFilter.CharLimit = 100
+	recentFilter.Focus()
+
 	searchInput := textinput.New()
 	searchInput.Placeholder = "type to search sessions"
 	searchInput.Prompt = ""
+	searchInput.PlaceholderStyle = st.muted
 	searchInput.CharLimit = 200
 
-	searchList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	searchList := list.New(nil, newSearchDelegate(th), 0, 0)
 	searchList.SetShowStatusBar(false)
 	searchList.SetFilteringEnabled(false)
 	searchList.SetShowHelp(false)
 	searchList.SetShowTitle(false)
 	searchList.DisableQuitKeybindings()
 
-	recentList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	recentList := list.New(nil, newDelegate(th), 0, 0)
 	recentList.SetShowStatusBar(false)
 	recentList.SetFilteringEnabled(false)
 	recentList.SetShowHelp(false)
 	recentList.SetShowTitle(false)
 	recentList.DisableQuitKeybindings()
 
-	projList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	projList := list.New(nil, newDelegate(th), 0, 0)
 	projList.SetShowStatusBar(false)
 	projList.SetFilteringEnabled(false)
 	projList.SetShowHelp(false)
 	projList.SetShowTitle(false)
 	projList.DisableQuitKeybindings()
 
-	modelList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	modelList := list.New(nil, newDelegate(th), 0, 0)
 	modelList.SetShowStatusBar(false)
 	modelList.SetFilteringEnabled(false)
 	modelList.SetShowHelp(false)
 	modelList.SetShowTitle(false)
 	modelList.DisableQuitKeybindings()
 
-	sesList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	sesList := list.New(nil, newDelegate(th), 0, 0)
 	sesList.SetShowStatusBar(false)
 	sesList.SetFilteringEnabled(false)
 	sesList.SetShowHelp(false)
@@ -234,6 +388,7 @@ func newModel(in Input) model {
 	sesList.DisableQuitKeybindings()
 
 	projectsAll := make([]opencodestorage.Project, 0, len(in.Projects))
+	projectDisplay := map[string]config.ProjectDisplay{}
 	var global *opencodestorage.Project
 	for i := range in.Projects {
 		p := in.Projects[i]
@@ -242,6 +397,13 @@ func newModel(in Input) model {
 			global = &in.Projects[i]
 			continue
 		}
+		if in.ProjectDisplay != nil {
+			d := in.ProjectDisplay(p.Worktree)
+			if d.Hide {
+				continue
+			}
+			projectDisplay[p.ID] = d
+		}
 		projectsAll = append(projectsAll, p)
 	}
 	if !in.HideGlobalProjects && global != nil {
@@ -249,13 +411,6 @@ func newModel(in Input) model {
 		projectsAll = append([]opencodestorage.Project{*global}, projectsAll...)
 	}
 
-	items := make([]list.Item, 0, len(projectsAll))
-	for _, p := range projectsAll {
-		items = append(items, projectItem{p})
-	}
-	projList.SetItems(items)
-	selectDefaultProject(&projList)
-
 	modelItems := make([]list.Item, 0, len(in.Models))
 	defaultIdx := 0
 	for i, m := range in.Models {
@@ -271,31 +426,84 @@ func newModel(in Input) model {
 	sesList.SetItems([]list.Item{sessionNewItem{}})
 	sesList.Select(0)
 
-	return model{
+	m := model{
 		store:                    in.Store,
 		hideGlobalProjects:       in.HideGlobalProjects,
 		globalSessionsMaxAgeDays: in.GlobalSessionsMaxAgeDays,
+		sessionLimits:            in.SessionLimits,
+		recentLimits:             in.RecentLimits,
+		pins:                     in.Pins,
+		notes:                    in.Notes,
+		editor:                   newNoteEditor(st),
+		projectScores:            state.Frecency(in.Launches, func(l state.Launch) string { return l.Project }, time.Now()),
+		modelScores:              state.Frecency(in.Launches, func(l state.Launch) string { return l.Model }, time.Now()),
+		frecentProjects:          in.Sort.FrecentProjects(),
+		frecentModels:            in.Sort.FrecentModels(),
+		matcher:                  matcher{substring: in.SubstringFilter},
+		mouse:                    in.Mouse && !in.Inline,
+		inline:                   in.Inline,
+		inlineLines:              in.InlineHeight,
+		vim:                      in.Vim,
+		cmdline:                  newCmdLine(),
 		viewMode:                 viewModeProjects,
 		projectsAll:              projectsAll,
+		projectDisplay:           projectDisplay,
 		sessionsByProject:        map[string][]opencodestorage.Session{},
 		loadingSessions:          map[string]bool{},
 		models:                   in.Models,
 		defaultModelIdx:          defaultIdx,
+		projectModels:            in.ProjectModels,
+		projectCache:             map[string]projectModelsResult{},
+		keys:                     newKeyMap(in.Keys),
+		shownModels:              config.ProjectModels{Models: in.Models, Default: in.DefaultModel},
 		focus:                    focusProjects,
 		projFilter:               projFilter,
 		sesFilter:                sesFilter,
 		searchInput:              searchInput,
 		searchList:               searchList,
+		searches:                 in.Searches,
+		searchHistoryMax:         in.SearchHistory,
+		searchHist:               -1,
+		searchName:               newSearchNameInput(st),
 		recentList:               recentList,
+		recentFilter:             recentFilter,
+		recentByProject:          in.RecentByProject,
 		projList:                 projList,
 		modelList:                modelList,
 		sesList:                  sesList,
 		styles:                   st,
 	}
+	if m.inline {
+		// Inline rows are one line each, so a page is exactly the rows shown.
+		for _, l := range []*list.Model{&m.projList, &m.sesList, &m.modelList, &m.recentList, &m.searchList} {
+			l.SetShowPagination(false)
+		}
+		m.resizeInline()
+	}
+	loadedAt := in.LoadedAt
+	if loadedAt.IsZero() {
+		loadedAt = time.Now()
+	}
+	m.refreshSources(loadedAt)
+	m.applyProjectFilter(true)
+	m.applyProjectModels()
+	if q := strings.TrimSpace(in.SearchQuery); q != "" {
+		m.openSearch()
+		m.searchInput.SetValue(q)
+		m.searchInput.CursorEnd()
+		m.searchLoading = true
+	}
+	return m
 }
 
 func (m model) Init() tea.Cmd {
-	return m.loadSessionsForSelectedProjectCmd()
+	load := m.loadSessionsForSelectedProjectCmd()
+	if !m.searchOpen {
+		return load
+	}
+	// Run the initial query through the debounced search path.
+	seq, q := m.searchSeq, m.searchInput.Value()
+	return tea.Batch(load, func() tea.Msg { return searchTickMsg{seq: seq, query: q} })
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -305,27 +513,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.resize()
 		return m, nil
 	case tea.MouseMsg:
-		// Mouse events are intentionally ignored (prevents scroll/click from
-		// messing up the UX; keyboard-first only).
-		return m, nil
+		if !m.mouse {
+			return m, nil
+		}
+		return m.updateMouse(msg)
 	case tea.KeyMsg:
+		if m.editor.open {
+			return m.updateNoteEditor(msg)
+		}
+		if m.help.open {
+			return m.updateHelp(msg)
+		}
+		if m.vim {
+			m.cmdNote = ""
+			if nm, cmd, ok := m.updateVim(msg); ok {
+				return nm, cmd
+			}
+		}
+		if m.canOpenHelp(msg) {
+			m.help = helpOverlay{open: true}
+			return m, nil
+		}
 		if m.searchOpen {
 			return m.updateSearch(msg)
 		}
-		switch msg.String() {
-		case "ctrl+r":
+		switch {
+		case key.Matches(msg, m.keys.Recent):
 			if m.viewMode == viewModeRecentSessions {
 				m.closeRecentSessions()
 				return m, nil
 			}
 			m.openRecentSessions()
 			return m, m.loadRecentSessionsCmd()
-		case "ctrl+p":
-			if m.viewMode == viewModeRecentSessions {
-				m.closeRecentSessions()
-				return m, nil
-			}
-		case "esc":
+		case key.Matches(msg, m.keys.Projects), key.Matches(msg, m.keys.Back):
 			if m.viewMode == viewModeRecentSessions {
 				m.closeRecentSessions()
 				return m, nil
@@ -334,27 +554,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.viewMode == viewModeRecentSessions {
 			return m.updateRecent(msg)
 		}
-		switch msg.String() {
-		case "ctrl+c":
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			m.plan = nil
 			return m, tea.Quit
-		case "ctrl+f", "alt+f", "meta+f", "cmd+f":
+		case key.Matches(msg, m.keys.Search):
 			m.openSearch()
 			return m, nil
-		case "ctrl+r":
-			// handled above (projects mode)
+		case key.Matches(msg, m.keys.RevealHidden):
+			m.revealHidden = !m.revealHidden
+			m.applySessionFilter(true)
+			m.ensureValidFocus()
+			m.updateFocus()
+			return m, nil
+		case key.Matches(msg, m.keys.Pin):
+			m.togglePin()
+			return m, nil
+		case key.Matches(msg, m.keys.Sort):
+			m.toggleSort()
 			return m, nil
-		case "tab":
+		case key.Matches(msg, m.keys.Collapse):
+			m.collapseOlder = !m.collapseOlder
+			m.applySessionFilter(false)
+			m.afterSessionMove("")
+			return m, nil
+		case key.Matches(msg, m.keys.EditNote):
+			if si, ok := m.sesList.SelectedItem().(sessionItem); ok && m.focus == focusSessions {
+				m.openNoteEditor(si.Session.ID, si.Session.Title)
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.NextFocus):
 			m.focus = m.nextFocus(1)
 			m.ensureValidFocus()
 			m.updateFocus()
 			return m, nil
-		case "shift+tab":
+		case key.Matches(msg, m.keys.PrevFocus):
 			m.focus = m.nextFocus(-1)
 			m.ensureValidFocus()
 			m.updateFocus()
 			return m, nil
-		case "enter":
+		case key.Matches(msg, m.keys.Launch):
 			if m.setPlanFromSelection() {
 				return m, tea.Quit
 			}
@@ -362,7 +601,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case sessionsLoadedMsg:
 		delete(m.loadingSessions, msg.projectID)
+		m.refreshSources(m.readAt)
 		if msg.err == nil {
+			m.readAt = time.Now()
 			m.sessionsByProject[msg.projectID] = msg.sessions
 			if p := m.selectedProject(); p != nil && p.ID == msg.projectID {
 				m.applySessionFilter(true)
@@ -370,22 +611,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case recentSessionsLoadedMsg:
+		if msg.seq != m.recentSeq || m.viewMode != viewModeRecentSessions {
+			// A page for an earlier opening of the view; ignore.
+			return m, nil
+		}
 		m.recentLoading = false
 		if msg.err != nil {
 			m.recentErr = msg.err.Error()
-			m.recentList.SetItems(nil)
+			m.recentMore = false
 			return m, nil
 		}
 		m.recentErr = ""
-		items := make([]list.Item, 0, len(msg.results))
-		for _, r := range msg.results {
-			items = append(items, recentSessionItem{res: r})
-		}
-		m.recentList.SetItems(items)
-		if len(items) > 0 {
-			m.recentList.Select(0)
+		m.refreshSources(time.Now())
+		m.recentAll = append(m.recentAll, msg.results...)
+		m.recentMore = msg.more
+		if n := len(msg.results); n > 0 {
+			m.recentCursor = msg.results[n-1].Cursor()
+		}
+		before := len(m.recentList.Items())
+		m.applyRecentLimits(false)
+		if len(m.recentList.Items()) == before {
+			// The filter or the policy hides the whole page; wait for the user
+			// to scroll again rather than reading the entire history.
+			return m, nil
 		}
-		return m, nil
+		return m, m.loadMoreRecentCmd()
 	case searchSpinMsg:
 		if !m.searchOpen {
 			m.searchSpinning = false
@@ -493,10 +743,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch m.focus {
 	case focusProjects:
-		oldID := ""
-		if p := m.selectedProject(); p != nil {
-			oldID = p.ID
-		}
+		oldID := m.selectedProjectID()
 
 		// Route navigation keys to the list; everything else to the filter.
 		if km, ok := msg.(tea.KeyMsg); ok && isNavKey(km) {
@@ -510,21 +757,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.projList, cmd2 = m.projList.Update(msg)
 		}
-
-		newID := ""
-		if p := m.selectedProject(); p != nil {
-			newID = p.ID
-		}
-		if newID != oldID {
-			m.sesFilter.SetValue("")
-			m.applySessionFilter(true)
-		}
-		cmd3 = m.loadSessionsForSelectedProjectCmd()
+		cmd3 = m.afterProjectMove(oldID)
 		return m, tea.Batch(cmd, cmd2, cmd3)
 	case focusSessions:
 		oldSessionID := m.selectedSessionID()
+		from := m.sesList.Index()
 		if km, ok := msg.(tea.KeyMsg); ok && isNavKey(km) {
 			m.sesList, cmd2 = m.sesList.Update(msg)
+			skipHeaders(&m.sesList, from)
 		} else {
 			before := m.sesFilter.Value()
 			m.sesFilter, cmd = m.sesFilter.Update(msg)
@@ -534,11 +774,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.sesList, cmd2 = m.sesList.Update(msg)
 		}
-
-		if m.selectedSessionID() != oldSessionID {
-			m.ensureValidFocus()
-			m.updateFocus()
-		}
+		m.afterSessionMove(oldSessionID)
 		return m, tea.Batch(cmd, cmd2)
 	case focusModels:
 		if m.modelLocked() {
@@ -551,105 +787,262 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// afterProjectMove refreshes the sessions and models when the selected
+// project changed from oldID, and loads its sessions if needed.
+func (m *model) afterProjectMove(oldID string) tea.Cmd {
+	if m.selectedProjectID() != oldID {
+		m.sesFilter.SetValue("")
+		m.applySessionFilter(true)
+		m.applyProjectModels()
+	}
+	return m.loadSessionsForSelectedProjectCmd()
+}
+
+// afterSessionMove updates focus rules (the model column locks for existing
+// sessions) when the selected session changed from oldID.
+func (m *model) afterSessionMove(oldID string) {
+	if m.selectedSessionID() != oldID {
+		m.ensureValidFocus()
+		m.updateFocus()
+	}
+}
+
 func (m *model) openRecentSessions() {
 	if m.viewMode == viewModeRecentSessions {
 		return
 	}
 	m.viewMode = viewModeRecentSessions
+	m.vimInsert = false
 	m.recentErr = ""
 	m.recentLoading = true
+	m.recentSeq++
+	m.recentCursor = opencodestorage.RecentCursor{}
+	m.recentMore = false
+	m.recentAll = nil
+	m.recentHidden = 0
+	m.recentFilter.SetValue("")
 	m.recentList.SetItems(nil)
 	m.recentList.Select(0)
 	m.resize()
 }
 
+// applyRecentLimits fills the recent list from the loaded sessions, the
+// recent sessions policy and the filter. Unless resetSelection is set, the
+// selected session stays selected.
+func (m *model) applyRecentLimits(resetSelection bool) {
+	// Pinned sessions are never hidden; the list stays in recency order.
+	var unpinned []opencodestorage.SessionSearchResult
+	for _, r := range m.recentAll {
+		if !m.pins.SessionPinned(r.Session.ID) {
+			unpinned = append(unpinned, r)
+		}
+	}
+	visible, hidden := limitSessions(unpinned, func(r opencodestorage.SessionSearchResult) opencodestorage.Session { return r.Session }, m.recentLimits, time.Now())
+	m.recentHidden = hidden
+	shown := make(map[string]bool, len(visible))
+	for _, r := range visible {
+		shown[r.Session.ID] = true
+	}
+	oldID := ""
+	if ri, ok := m.recentList.SelectedItem().(recentSessionItem); ok && !resetSelection {
+		oldID = ri.res.Session.ID
+	}
+	tags, q := splitTagQuery(strings.ToLower(strings.TrimSpace(m.recentFilter.Value())))
+
+	// Without a text query, sessions are grouped by date or project; matches
+	// are ranked instead.
+	items := make([]list.Item, 0, len(m.recentAll))
+	var ranked []scoredItem
+	for _, r := range m.recentAll {
+		pinned := m.pins.SessionPinned(r.Session.ID)
+		if !pinned && !shown[r.Session.ID] && !m.revealHidden {
+			continue
+		}
+		ri := recentSessionItem{res: r, pinned: pinned, note: m.notes.Get(r.Session.ID)}
+		if !hasTags(ri.note, tags) {
+			continue
+		}
+		if q == "" {
+			items = append(items, ri)
+			continue
+		}
+		if score, runes, ok := m.matcher.match(q, ri.Title(), ri.Description()); ok {
+			ri.matched = runes
+			ranked = append(ranked, scoredItem{ri, score})
+		}
+	}
+	switch {
+	case q != "":
+		items = rankItems(ranked)
+	case m.recentByProject:
+		items = m.groupByProject(items)
+	default:
+		items = groupByDate(items, func(it list.Item) int64 { return it.(recentSessionItem).res.Session.Updated }, time.Now(), m.collapseOlder)
+	}
+	m.recentList.SetItems(items)
+	m.recentList.Select(0)
+	for i, it := range items {
+		if ri, ok := it.(recentSessionItem); ok && oldID != "" && ri.res.Session.ID == oldID {
+			m.recentList.Select(i)
+		}
+	}
+	skipHeaders(&m.recentList, 0)
+}
+
 func (m *model) closeRecentSessions() {
 	if m.viewMode != viewModeRecentSessions {
 		return
 	}
 	m.viewMode = viewModeProjects
+	m.vimInsert = false
 	m.recentLoading = false
 	m.recentErr = ""
 	m.resize()
 }
 
+// loadRecentSessionsCmd loads the page of recent sessions after
+// m.recentCursor.
 func (m model) loadRecentSessionsCmd() tea.Cmd {
-	store := m.store
+	store, seq, cursor := m.store, m.recentSeq, m.recentCursor
 	if store == nil {
-		return func() tea.Msg { return recentSessionsLoadedMsg{results: nil, err: fmt.Errorf("no storage configured")} }
+		return func() tea.Msg {
+			return recentSessionsLoadedMsg{seq: seq, results: nil, err: fmt.Errorf("no storage configured")}
+		}
 	}
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		res, err := store.RecentSessions(ctx, 200)
-		return recentSessionsLoadedMsg{results: res, err: err}
+		res, more, err := store.RecentSessionsBefore(ctx, cursor, recentPageSize)
+		return recentSessionsLoadedMsg{seq: seq, results: res, more: more, err: err}
 	}
 }
 
 func (m model) updateRecent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		m.plan = nil
 		return m, tea.Quit
-	case "ctrl+f", "alt+f", "meta+f", "cmd+f":
+	case key.Matches(msg, m.keys.Search):
 		m.openSearch()
 		return m, nil
-	case "enter":
-		it := m.recentList.SelectedItem()
-		if it == nil {
-			return m, nil
+	case key.Matches(msg, m.keys.RevealHidden):
+		m.revealHidden = !m.revealHidden
+		m.applyRecentLimits(false)
+		return m, nil
+	case key.Matches(msg, m.keys.Pin):
+		if ri, ok := m.recentList.SelectedItem().(recentSessionItem); ok {
+			m.toggleSessionPin(ri.res.Session.ID)
 		}
-		ri, ok := it.(recentSessionItem)
-		if !ok {
-			return m, nil
+		return m, nil
+	case key.Matches(msg, m.keys.Collapse):
+		m.collapseOlder = !m.collapseOlder
+		m.applyRecentLimits(false)
+		return m, nil
+	case key.Matches(msg, m.keys.EditNote):
+		if ri, ok := m.recentList.SelectedItem().(recentSessionItem); ok {
+			m.openNoteEditor(ri.res.Session.ID, ri.Title())
 		}
-		m.plan = &LaunchPlan{
-			ProjectDir: ri.res.ProjectWorktree,
-			Model:      m.models[m.defaultModelIdx],
-			SessionID:  ri.res.Session.ID,
+		return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		if m.launchRecentSelection() {
+			return m, tea.Quit
 		}
-		return m, tea.Quit
+		return m, nil
 	}
 
+	// Route navigation keys to the list; everything else to the filter. Only
+	// scrolling loads more pages.
 	var cmd tea.Cmd
-	m.recentList, cmd = m.recentList.Update(msg)
+	if isNavKey(msg) {
+		from := m.recentList.Index()
+		m.recentList, cmd = m.recentList.Update(msg)
+		skipHeaders(&m.recentList, from)
+		return m, tea.Batch(cmd, m.loadMoreRecentCmd())
+	}
+	before := m.recentFilter.Value()
+	m.recentFilter, cmd = m.recentFilter.Update(msg)
+	if m.recentFilter.Value() != before {
+		m.applyRecentLimits(true)
+	}
 	return m, cmd
 }
 
-func (m model) viewRecentSessions() string {
-	header := m.helpLine([]helpBinding{
-		{key: "esc", text: "back"},
-		{key: "enter", text: "launch"},
-		{key: "ctrl+f", text: "search"},
-		{key: "ctrl+p", text: "projects"},
-		{key: "ctrl+c", text: "quit"},
-	}, "")
-
-	status := ""
-	if m.recentErr != "" {
-		status = m.styles.muted.Render("error: " + m.recentErr)
-	} else if m.recentLoading {
-		status = m.styles.muted.Render("loading...")
-	} else if len(m.recentList.Items()) == 0 {
-		status = m.styles.muted.Render("no sessions")
+// launchRecentSelection plans a launch of the selected recent session.
+func (m *model) launchRecentSelection() bool {
+	ri, ok := m.recentList.SelectedItem().(recentSessionItem)
+	if !ok {
+		return false
+	}
+	// No preferred model: launch with the project's default.
+	mdl, profile := m.launchSelection(ri.res.ProjectWorktree, config.Model{}, nil)
+	m.plan = &LaunchPlan{
+		ProjectDir: ri.res.ProjectWorktree,
+		Model:      mdl,
+		SessionID:  ri.res.Session.ID,
+		Profile:    profile,
+	}
+	return true
+}
+
+// recentStatus is the line shown above the recent sessions, if any.
+func (m model) recentStatus() string {
+	switch {
+	case m.recentErr != "":
+		return m.styles.muted.Render("error: " + m.recentErr)
+	case m.recentLoading && len(m.recentAll) > 0:
+		return m.styles.muted.Render(fmt.Sprintf("loading more... (%d loaded)", len(m.recentAll)))
+	case m.recentLoading:
+		return m.styles.muted.Render("loading...")
+	case len(m.recentList.Items()) == 0 && m.recentFilter.Value() != "":
+		return m.styles.muted.Render("no matches")
+	case len(m.recentList.Items()) == 0:
+		return m.styles.muted.Render("no sessions")
+	}
+	return ""
+}
+
+// recentHelp returns the help line bindings and hint of the recent sessions
+// view.
+func (m model) recentHelp() ([]helpBinding, string) {
+	bindings := []helpBinding{
+		bind(m.keys.Back, "back"),
+		bind(m.keys.Launch, "launch"),
+		bind(m.keys.Search, "search"),
+		bind(m.keys.Projects, "projects"),
+	}
+	if m.pins != nil {
+		bindings = append(bindings, bind(m.keys.Pin, "pin"))
 	}
+	if m.notes != nil {
+		bindings = append(bindings, bind(m.keys.EditNote, "tags/note"))
+	}
+	if hasCollapsible(m.recentList.Items()) {
+		bindings = append(bindings, m.collapseBinding())
+	}
+	if m.recentHidden > 0 {
+		bindings = append(bindings, m.revealBinding())
+	}
+	return append(bindings, bind(m.keys.Help, "help"), bind(m.keys.Quit, "quit")), "(type to filter)"
+}
+
+func (m model) viewRecentSessions() string {
+	header := m.header(m.recentHelp())
+
+	status := m.recentStatus()
 
 	fullW := m.width - outerMarginLeft - outerMarginRight - m.safetySlack()
 	if fullW < 0 {
 		fullW = 0
 	}
 	panelW := maxInt(20, fullW)
-	content := m.title("Recent Sessions", true)
+	content := m.title("Recent Sessions"+m.hiddenSuffix(m.recentHidden), true) + "\n" + m.filterLine(m.recentFilter.Value())
 	if strings.TrimSpace(status) != "" {
 		content += "\n" + status
 	}
 	content += "\n" + m.recentList.View()
 	panel := m.panelW(true, panelW, m.panelHeight, content)
 
-	if m.layoutMode() == layoutModeNarrow {
-		return strings.TrimRight(m.inset(header+"\n"+panel), "\n")
-	}
-	return strings.TrimRight(m.inset(header+"\n\n"+panel), "\n")
+	return m.screen(header, panel)
 }
 
 func isNavKey(k tea.KeyMsg) bool {
@@ -668,9 +1061,8 @@ type helpBinding struct {
 }
 
 func (m model) helpLine(bindings []helpBinding, tail string) string {
-	// Match the list selection color (DefaultItemStyles.SelectedTitle).
-	accent := lipgloss.NewStyle().Foreground(lipgloss.Color("#EE6FF8"))
-	muted := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	accent := m.styles.key
+	muted := m.styles.muted
 	parts := make([]string, 0, len(bindings)+1)
 	for _, b := range bindings {
 		k := strings.TrimSpace(b.key)
@@ -691,6 +1083,7 @@ func (m *model) openSearch() {
 		return
 	}
 	m.searchOpen = true
+	m.vimInsert = m.vim
 	m.searchPrevFocus = m.focus
 	m.searchInput.SetValue("")
 	m.searchInput.Focus()
@@ -703,6 +1096,13 @@ func (m *model) openSearch() {
 	m.searchScanLimit = 0
 	m.searchSpinIdx = 0
 	m.searchSpinning = false
+	m.searchExpanded = false
+	m.searchMatchID = ""
+	m.searchMatchIdx = 0
+	m.searchHist = -1
+	m.searchNote = ""
+	m.searchPicking = false
+	m.searchNaming = false
 	if m.searchCancel != nil {
 		m.searchCancel()
 		m.searchCancel = nil
@@ -717,7 +1117,11 @@ func (m *model) closeSearch() {
 	if !m.searchOpen {
 		return
 	}
+	m.rememberSearch()
 	m.searchOpen = false
+	m.vimInsert = false
+	m.searchPicking = false
+	m.searchNaming = false
 	if m.searchCancel != nil {
 		m.searchCancel()
 		m.searchCancel = nil
@@ -737,41 +1141,75 @@ func (m *model) closeSearch() {
 }
 
 func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	m.searchNote = ""
+	if m.searchNaming {
+		return m.updateSearchName(msg)
+	}
+	if m.searchPicking {
+		return m.updateSavedSearches(msg)
+	}
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		m.plan = nil
 		return m, tea.Quit
-	case "esc":
+	case key.Matches(msg, m.keys.Back):
 		m.closeSearch()
 		return m, nil
-	case "ctrl+f", "alt+f", "meta+f", "cmd+f":
+	case key.Matches(msg, m.keys.Search):
 		// Toggle.
 		m.closeSearch()
 		return m, nil
-	case "enter":
-		it := m.searchList.SelectedItem()
-		if it == nil {
-			return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		if m.launchSearchSelection() {
+			return m, tea.Quit
 		}
-		si, ok := it.(sessionSearchItem)
-		if !ok {
-			return m, nil
+		return m, nil
+	case key.Matches(msg, m.keys.SearchOrder):
+		if m.searchOrder == opencodestorage.SearchOrderRelevance {
+			m.searchOrder = opencodestorage.SearchOrderNewest
+		} else {
+			m.searchOrder = opencodestorage.SearchOrderRelevance
 		}
-		m.plan = &LaunchPlan{
-			ProjectDir: si.res.ProjectWorktree,
-			Model:      m.selectedModel(),
-			SessionID:  si.res.Session.ID,
+		// Re-run the current query with the new ordering.
+		m.searchSeq++
+		return m.startSearch(m.searchInput.Value())
+	case key.Matches(msg, m.keys.SearchMatches):
+		m.searchExpanded = !m.searchExpanded
+		m.syncSearchMatchIdx()
+		m.resize()
+		return m, nil
+	case m.searchExpanded && key.Matches(msg, m.keys.NextMatch):
+		m.stepSearchMatch(1)
+		return m, nil
+	case m.searchExpanded && key.Matches(msg, m.keys.PrevMatch):
+		m.stepSearchMatch(-1)
+		return m, nil
+	case key.Matches(msg, m.keys.SaveSearch):
+		switch {
+		case m.searches == nil:
+		case strings.TrimSpace(m.searchInput.Value()) == "":
+			m.openSavedSearches()
+		default:
+			m.openSearchName()
+		}
+		return m, nil
+	case msg.Type == tea.KeyUp, msg.Type == tea.KeyDown:
+		delta := 1
+		if msg.Type == tea.KeyDown {
+			delta = -1
+		}
+		if nm, cmd, ok := m.recallSearch(delta); ok {
+			return nm, cmd
 		}
-		return m, tea.Quit
 	}
 
 	var cmd1 tea.Cmd
 	var cmd2 tea.Cmd
 
-	// Route nav keys to results; everything else to the search box.
-	if isNavKey(msg) {
-		m.searchList, cmd1 = m.searchList.Update(msg)
-		return m, cmd1
+	// Route nav keys to results; everything else to the search box. left and
+	// right move the cursor in the query; pgup/pgdown still page the results.
+	if isNavKey(msg) && msg.Type != tea.KeyLeft && msg.Type != tea.KeyRight {
+		return m.moveSearchList(msg)
 	}
 
 	before := m.searchInput.Value()
@@ -795,10 +1233,30 @@ func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// Allow scrolling the results (pgup/pgdown) even when typing.
+	if msg.Type == tea.KeyLeft || msg.Type == tea.KeyRight {
+		return m, cmd1
+	}
 	m.searchList, cmd2 = m.searchList.Update(msg)
 	return m, tea.Batch(cmd1, cmd2)
 }
 
+// launchSearchSelection plans a launch of the selected search result.
+func (m *model) launchSearchSelection() bool {
+	si, ok := m.searchList.SelectedItem().(sessionSearchItem)
+	if !ok {
+		return false
+	}
+	m.rememberSearch()
+	mdl, profile := m.launchSelection(si.res.ProjectWorktree, m.selectedModel(), m.selectedProfile())
+	m.plan = &LaunchPlan{
+		ProjectDir: si.res.ProjectWorktree,
+		Model:      mdl,
+		SessionID:  si.res.Session.ID,
+		Profile:    profile,
+	}
+	return true
+}
+
 func (m model) searchSpinCmd() tea.Cmd {
 	return tea.Tick(120*time.Millisecond, func(time.Time) tea.Msg {
 		return searchSpinMsg{}
@@ -822,6 +1280,7 @@ func (m model) startSearch(query string) (model, tea.Cmd) {
 	m.searchSpinIdx = 0
 	m.searchList.SetItems(nil)
 	m.searchList.Select(0)
+	m.searchMoved = false
 
 	if query == "" {
 		m.searchLoading = false
@@ -877,65 +1336,158 @@ func (m model) startSearchStage(query string, stage int) (model, tea.Cmd) {
 	store := m.store
 	limit := 50
 	candidateLimit := searchStages[stage]
+	order := m.searchOrder
 	return m, func() tea.Msg {
 		defer cancel()
 		if store == nil {
 			return searchResultsMsg{query: query, results: nil, err: fmt.Errorf("no storage configured"), stage: stage, candidateLimit: candidateLimit}
 		}
 		if w, ok := store.(opencodestorage.WindowSearchStore); ok {
-			res, err := w.SearchSessionsWindow(ctx, query, limit, candidateLimit)
+			res, err := w.SearchSessionsWindow(ctx, query, limit, candidateLimit, order)
 			return searchResultsMsg{query: query, results: res, err: err, stage: stage, candidateLimit: candidateLimit}
 		}
-		res, err := store.SearchSessions(ctx, query, limit)
+		res, err := store.SearchSessions(ctx, query, limit, order)
 		return searchResultsMsg{query: query, results: res, err: err, stage: stage, candidateLimit: candidateLimit}
 	}
 }
 
-func (m model) viewSearch() string {
-	header := m.helpLine([]helpBinding{
-		{key: "esc", text: "close"},
-		{key: "enter", text: "launch"},
-		{key: "ctrl+c", text: "quit"},
-	}, "(type to search)")
-
-	q := strings.TrimSpace(m.searchInput.Value())
-	searchLine := m.styles.muted.Render("type to search")
-	if q != "" {
-		searchLine = m.styles.muted.Render("search: " + q)
-	}
-	status := ""
-	if m.searchErr != "" {
-		status = m.styles.muted.Render("error: " + m.searchErr)
-	} else if m.searchLoading {
+// searchStatus is the line shown above the search results, if any.
+func (m model) searchStatus() string {
+	switch {
+	case m.searchErr != "":
+		return m.styles.muted.Render("error: " + m.searchErr)
+	case m.searchesErr != "":
+		return m.styles.muted.Render("searches not saved: " + m.searchesErr)
+	case m.searchNote != "":
+		return m.styles.muted.Render(m.searchNote)
+	case m.searchLoading:
 		spin := ""
 		if m.searchSpinning {
 			chars := []string{"|", "/", "-", "\\"}
 			spin = " " + chars[m.searchSpinIdx%len(chars)]
 		}
-		status = m.styles.muted.Render("searching " + searchStageLabel(m.searchScanLimit) + "..." + spin)
-	} else if q != "" && len(m.searchList.Items()) == 0 {
-		status = m.styles.muted.Render("no matches")
+		return m.styles.muted.Render("searching " + searchStageLabel(m.searchScanLimit) + "..." + spin)
+	case strings.TrimSpace(m.searchInput.Value()) != "" && len(m.searchList.Items()) == 0:
+		return m.styles.muted.Render("no matches")
 	}
+	return ""
+}
+
+// searchHelp returns the help line bindings and hint of the search view.
+func (m model) searchHelp() ([]helpBinding, string) {
+	q := strings.TrimSpace(m.searchInput.Value())
+	var bindings []helpBinding
+	tail := "(type to search)"
+	switch {
+	case m.searchNaming:
+		bindings = []helpBinding{bind(m.keys.Launch, "save"), bind(m.keys.Back, "cancel"), bind(m.keys.Quit, "quit")}
+		tail = ""
+	case m.searchPicking:
+		bindings = []helpBinding{
+			bind(m.keys.Launch, "run"),
+			{key: "delete", text: "remove"},
+			bind(m.keys.Back, "close"),
+			bind(m.keys.Help, "help"),
+			bind(m.keys.Quit, "quit"),
+		}
+	default:
+		bindings = []helpBinding{
+			bind(m.keys.Back, "close"),
+			bind(m.keys.Launch, "launch"),
+			bind(m.keys.SearchMatches, "matches"),
+			bind(m.keys.SearchOrder, "order: "+m.searchOrder.String()),
+		}
+		if m.searches != nil {
+			if q == "" {
+				bindings = append(bindings, bind(m.keys.SaveSearch, "saved"))
+			} else {
+				bindings = append(bindings, bind(m.keys.SaveSearch, "save"))
+			}
+		}
+		if m.searchExpanded {
+			bindings = append(bindings, bindPair(m.keys.PrevMatch, m.keys.NextMatch, "step"))
+		}
+		bindings = append(bindings, bind(m.keys.Help, "help"), bind(m.keys.Quit, "quit"))
+	}
+	return bindings, tail
+}
+
+func (m model) viewSearch() string {
+	header := m.header(m.searchHelp())
+	q := strings.TrimSpace(m.searchInput.Value())
+
+	searchLine := m.styles.muted.Render("type to search")
+	if len(m.searches.Recent()) > 0 {
+		searchLine = m.styles.muted.Render("type to search, up for recent queries")
+	}
+	switch {
+	case m.searchNaming:
+		searchLine = m.searchName.View()
+	case q != "":
+		searchLine = m.styles.muted.Render("search: " + q)
+	}
+	status := m.searchStatus()
 
 	fullW := m.width - outerMarginLeft - outerMarginRight - m.safetySlack()
 	if fullW < 0 {
 		fullW = 0
 	}
 	panelW := maxInt(20, fullW)
-	content := m.title("Search Sessions", true) + "\n" + searchLine
+	title := "Search Sessions"
+	switch {
+	case m.searchPicking:
+		title = "Saved Searches"
+	case m.searchOrder == opencodestorage.SearchOrderRelevance:
+		title += " (best match)"
+	}
+	content := m.title(title, true) + "\n" + searchLine
 	if strings.TrimSpace(status) != "" {
 		content += "\n" + status
 	}
 	content += "\n" + m.searchList.View()
+	if m.searchExpanded {
+		content += "\n" + m.viewSearchMatches(maxInt(10, panelW-4))
+	}
 	panel := m.panelW(true, panelW, m.panelHeight, content)
 
-	if m.layoutMode() == layoutModeNarrow {
-		return strings.TrimRight(m.inset(header+"\n"+panel), "\n")
+	return m.screen(header, panel)
+}
+
+// projectsHelp returns the help line bindings and hint of the projects view.
+func (m model) projectsHelp() ([]helpBinding, string) {
+	bindings := []helpBinding{
+		bind(m.keys.NextFocus, "next"),
+		bind(m.keys.PrevFocus, "prev"),
+		bind(m.keys.Launch, "launch"),
+		bind(m.keys.Search, "global search"),
+		bind(m.keys.Recent, "recent"),
+	}
+	if m.pins != nil && m.focus != focusModels {
+		bindings = append(bindings, bind(m.keys.Pin, "pin"))
+	}
+	if m.notes != nil && m.focus == focusSessions && m.selectedSessionID() != "" {
+		bindings = append(bindings, bind(m.keys.EditNote, "tags/note"))
+	}
+	if m.focus == focusSessions && hasCollapsible(m.sesList.Items()) {
+		bindings = append(bindings, m.collapseBinding())
 	}
-	return strings.TrimRight(m.inset(header+"\n\n"+panel), "\n")
+	bindings = append(bindings, m.sortBinding())
+	if m.sesHidden > 0 {
+		bindings = append(bindings, m.revealBinding())
+	}
+	return append(bindings, bind(m.keys.Help, "help"), bind(m.keys.Quit, "quit")), "(type to filter)"
 }
 
 func (m model) View() string {
+	if m.inline {
+		return m.viewInline()
+	}
+	if m.editor.open {
+		return m.viewNoteEditor()
+	}
+	if m.help.open {
+		return m.viewHelp()
+	}
 	if m.searchOpen {
 		return m.viewSearch()
 	}
@@ -943,18 +1495,11 @@ func (m model) View() string {
 		return m.viewRecentSessions()
 	}
 
-	header := m.helpLine([]helpBinding{
-		{key: "tab", text: "next"},
-		{key: "shift+tab", text: "prev"},
-		{key: "enter", text: "launch"},
-		{key: "ctrl+f", text: "global search"},
-		{key: "ctrl+r", text: "recent"},
-		{key: "ctrl+c", text: "quit"},
-	}, "(type to filter)")
+	header := m.header(m.projectsHelp())
 
-	projTitle := m.title("Projects", m.focus == focusProjects)
-	sesTitle := m.title("Sessions", m.focus == focusSessions)
-	modelTitle := m.title("Model", m.focus == focusModels)
+	projTitle := m.title("Projects"+frecentSuffix(m.frecentProjects), m.focus == focusProjects)
+	sesTitle := m.title("Sessions"+m.hiddenSuffix(m.sesHidden), m.focus == focusSessions)
+	modelTitle := m.title("Model"+frecentSuffix(m.frecentModels), m.focus == focusModels)
 	if m.modelLocked() {
 		modelTitle = m.title("Model (locked)", false)
 	}
@@ -970,15 +1515,26 @@ func (m model) View() string {
 	} else {
 		modelBody = m.modelList.View()
 	}
+	if m.modelErr != "" {
+		modelBody = m.styles.muted.Render(truncateANSI("config: "+m.modelErr, maxInt(10, m.colWModel-4))) + "\n" + modelBody
+	}
 	modelPanel := m.panelW(m.focus == focusModels && !m.modelLocked(), m.colWModel, m.panelHeight, modelTitle+"\n"+modelBody)
 
 	projPanel := m.panelW(m.focus == focusProjects, m.colWProj, m.panelHeight, projTitle+"\n"+m.filterLine(m.projFilter.Value())+"\n"+m.projList.View())
 
 	content := m.layout(projPanel, sesPanel, modelPanel)
+	return m.screen(header, content)
+}
+
+// screen lays out a full-screen view: the help line, the body and the status
+// bar, inset by the outer margins.
+func (m model) screen(header, body string) string {
+	gap := "\n\n"
 	if m.layoutMode() == layoutModeNarrow {
-		return strings.TrimRight(m.inset(header+"\n"+content), "\n")
+		gap = "\n"
 	}
-	return strings.TrimRight(m.inset(header+"\n\n"+content), "\n")
+	bar := m.statusBar(maxInt(0, m.width-outerMarginLeft-outerMarginRight-m.safetySlack()))
+	return strings.TrimRight(m.inset(header+gap+strings.TrimRight(body, "\n")+"\n"+bar), "\n")
 }
 
 func (m model) inset(s string) string {
@@ -1044,11 +1600,16 @@ func (m model) filterLine(value string) string {
 }
 
 func (m *model) resize() {
+	if m.inline {
+		m.resizeInline()
+		return
+	}
 	mode := m.layoutMode()
-	// Reserve space for the top help line and narrow-mode context.
-	reserved := 4
+	// Reserve space for the top help line, the status bar and narrow-mode
+	// context.
+	reserved := 5
 	if mode == layoutModeNarrow {
-		reserved = 5
+		reserved = 6
 	}
 	height := m.height - reserved
 	if height < 8 {
@@ -1062,9 +1623,13 @@ func (m *model) resize() {
 		fullW = 0
 	}
 	innerW := maxInt(10, fullW-4)
-	m.searchList.SetSize(innerW, maxInt(3, height-3))
-	// Recent sessions view uses a full-width list.
-	m.recentList.SetSize(innerW, maxInt(3, height-3))
+	searchListH := height - 3
+	if m.searchExpanded {
+		searchListH -= searchMatchesHeight
+	}
+	m.searchList.SetSize(innerW, maxInt(3, searchListH))
+	// Recent sessions view uses a full-width list below its filter line.
+	m.recentList.SetSize(innerW, maxInt(3, height-4))
 
 	if mode == layoutModeNarrow {
 		available := m.width - outerMarginLeft - outerMarginRight - 2*colGapSpaces - m.safetySlack()
@@ -1110,7 +1675,7 @@ func (m *model) resize() {
 		innerActiveW := maxInt(10, activeW-4)
 		m.projList.SetSize(innerActiveW, maxInt(3, height-2))
 		m.sesList.SetSize(innerActiveW, maxInt(3, height-2))
-		m.modelList.SetSize(innerActiveW, maxInt(3, height-1))
+		m.modelList.SetSize(innerActiveW, maxInt(3, height-1-m.modelErrLines()))
 		return
 	}
 
@@ -1130,7 +1695,7 @@ func (m *model) resize() {
 	if maxModelAllowed < minColWModel {
 		maxModelAllowed = minColWModel
 	}
-	modelW := clampInt(maxModelLineLen(m.models)+8, minColWModel, maxModelAllowed)
+	modelW := clampInt(maxInt(maxModelLineLen(m.models), maxItemLineLen(m.modelList.Items()))+8, minColWModel, maxModelAllowed)
 	projW := clampInt(maxProjectLineLen(m.projList.Items())+8, minColWProjects, maxColWProjectsCap)
 	remaining := available - modelW
 	if remaining < 0 {
@@ -1165,13 +1730,21 @@ func (m *model) resize() {
 
 	projListH := maxInt(3, height-2)
 	sesListH := maxInt(3, height-2)
-	modelListH := maxInt(3, height-1)
+	modelListH := maxInt(3, height-1-m.modelErrLines())
 
 	m.projList.SetSize(innerProjW, projListH)
 	m.sesList.SetSize(innerSesW, sesListH)
 	m.modelList.SetSize(innerModelW, modelListH)
 }
 
+// modelErrLines is the height of the config error line in the model column.
+func (m model) modelErrLines() int {
+	if m.modelErr == "" {
+		return 0
+	}
+	return 1
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
@@ -1202,13 +1775,30 @@ func maxModelLineLen(models []config.Model) int {
 		if l := len(m.Name); l > maxLen {
 			maxLen = l
 		}
-		if l := len(m.Model); l > maxLen {
+		l := len(m.Model)
+		if m.Discovered {
+			l += len(discoveredSuffix)
+		}
+		if l > maxLen {
 			maxLen = l
 		}
 	}
 	return maxLen
 }
 
+// maxItemLineLen returns the widest title or description among items.
+func maxItemLineLen(items []list.Item) int {
+	maxLen := 0
+	for _, it := range items {
+		di, ok := it.(list.DefaultItem)
+		if !ok {
+			continue
+		}
+		maxLen = maxInt(maxLen, maxInt(len(di.Title()), len(di.Description())))
+	}
+	return maxLen
+}
+
 func maxProjectLineLen(items []list.Item) int {
 	maxLen := 0
 	for _, it := range items {
@@ -1347,7 +1937,7 @@ func (m model) selectedProjectLabel() string {
 	if p == nil {
 		return "-"
 	}
-	return projectItem{*p}.Title()
+	return m.newProjectItem(*p).Title()
 }
 
 func (m model) selectedSessionLabel() string {
@@ -1371,6 +1961,9 @@ func (m model) selectedSessionLabel() string {
 func (m model) selectedModelLabel() string {
 	sel := m.selectedModel()
 	s := strings.TrimSpace(sel.Name)
+	if p := m.selectedProfile(); p != nil {
+		s = p.Name + " (" + s + ")"
+	}
 	if s == "" {
 		s = strings.TrimSpace(sel.Model)
 	}
@@ -1390,12 +1983,12 @@ func truncateANSI(s string, maxW int) string {
 	return ansi.Truncate(s, maxW, "...")
 }
 
+// applyProjectFilter rebuilds the project list: pinned projects, then the
+// rest in recency (or frecency) order. A query keeps the matches, exact
+// aliases first and the rest ranked by score. Without resetSelection the
+// selected project stays selected.
 func (m *model) applyProjectFilter(resetSelection bool) {
 	q := strings.ToLower(strings.TrimSpace(m.projFilter.Value()))
-	if !resetSelection && q == m.lastProjQuery {
-		return
-	}
-	m.lastProjQuery = q
 
 	oldID := ""
 	if !resetSelection {
@@ -1404,16 +1997,37 @@ func (m *model) applyProjectFilter(resetSelection bool) {
 		}
 	}
 
-	items := make([]list.Item, 0, len(m.projectsAll))
+	var pinned, rest []list.Item
 	for _, p := range m.projectsAll {
-		pi := projectItem{p}
-		if q == "" {
-			items = append(items, pi)
-			continue
+		if pi := m.newProjectItem(p); pi.pinned {
+			pinned = append(pinned, pi)
+		} else {
+			rest = append(rest, pi)
 		}
-		if strings.Contains(strings.ToLower(pi.Title()), q) || strings.Contains(strings.ToLower(pi.Description()), q) {
-			items = append(items, pi)
+	}
+	if m.frecentProjects {
+		m.sortProjectsByFrecency(pinned)
+		m.sortProjectsByFrecency(rest)
+	}
+	items := append(pinned, rest...)
+	if q != "" {
+		var aliased []list.Item
+		var ranked []scoredItem
+		for _, it := range items {
+			pi := it.(projectItem)
+			score, runes, ok := pi.match(m.matcher, q)
+			if !ok {
+				continue
+			}
+			pi.matched = runes
+			if pi.hasAlias(q) {
+				// An exact alias jumps to the top.
+				aliased = append(aliased, pi)
+				continue
+			}
+			ranked = append(ranked, scoredItem{pi, score})
 		}
+		items = append(aliased, rankItems(ranked)...)
 	}
 	m.projList.SetItems(items)
 	if len(items) == 0 {
@@ -1439,43 +2053,131 @@ func (m *model) applySessionFilter(resetSelection bool) {
 	p := m.selectedProject()
 	items := []list.Item{sessionNewItem{}}
 	if p == nil {
+		m.sesHidden = 0
 		m.sesList.SetItems(items)
 		m.sesList.Select(0)
 		return
 	}
-	all := m.sessionsByProject[p.ID]
+	oldID := m.selectedSessionID()
 	isGlobal := isGlobalProject(*p)
-	// Optional noise reduction for Global sessions.
-	if isGlobal && m.globalSessionsMaxAgeDays > 0 {
-		cutoff := time.Now().Add(-time.Duration(m.globalSessionsMaxAgeDays) * 24 * time.Hour).UnixMilli()
-		filtered := make([]opencodestorage.Session, 0, len(all))
-		for _, s := range all {
-			if s.Updated >= cutoff {
-				filtered = append(filtered, s)
-			}
+
+	// Pinned sessions are listed first and never hidden by the policy.
+	var pinned, rest []opencodestorage.Session
+	for _, s := range m.sessionsByProject[p.ID] {
+		if m.pins.SessionPinned(s.ID) {
+			pinned = append(pinned, s)
+		} else {
+			rest = append(rest, s)
 		}
-		all = filtered
 	}
-	q := strings.ToLower(strings.TrimSpace(m.sesFilter.Value()))
-	if !resetSelection && q == m.lastSesQuery {
-		return
+	visible, hidden := limitSessions(rest, func(s opencodestorage.Session) opencodestorage.Session { return s }, m.sessionLimitsFor(*p), time.Now())
+	m.sesHidden = hidden
+	if m.revealHidden {
+		visible = rest
 	}
-	m.lastSesQuery = q
+	tags, q := splitTagQuery(strings.ToLower(strings.TrimSpace(m.sesFilter.Value())))
 
-	for _, s := range all {
-		si := sessionItem{Session: s, showDir: isGlobal}
+	// Without a text query, sessions are grouped by date under headers;
+	// matches are ranked instead.
+	var ranked []scoredItem
+	var pinnedItems, dated []list.Item
+	for _, s := range append(pinned, visible...) {
+		si := sessionItem{Session: s, showDir: isGlobal, pinned: m.pins.SessionPinned(s.ID), note: m.notes.Get(s.ID)}
+		if !hasTags(si.note, tags) {
+			continue
+		}
 		if q == "" {
-			items = append(items, si)
+			if si.pinned {
+				pinnedItems = append(pinnedItems, si)
+			} else {
+				dated = append(dated, si)
+			}
 			continue
 		}
-		if strings.Contains(strings.ToLower(si.Title()), q) || strings.Contains(strings.ToLower(si.Description()), q) {
-			items = append(items, si)
+		score, runes, ok := m.matcher.match(q, si.Title(), si.Description())
+		if ok {
+			si.matched = runes
+			ranked = append(ranked, scoredItem{si, score})
 		}
 	}
+	if len(pinnedItems) > 0 {
+		items = append(items, groupHeader{label: groupPinned, count: len(pinnedItems)})
+		items = append(items, pinnedItems...)
+	}
+	items = append(items, groupByDate(dated, func(it list.Item) int64 { return it.(sessionItem).Session.Updated }, time.Now(), m.collapseOlder)...)
+	items = append(items, rankItems(ranked)...)
 	m.sesList.SetItems(items)
 	if resetSelection {
 		m.sesList.Select(0)
+		return
+	}
+	for i, it := range items {
+		if si, ok := it.(sessionItem); ok && si.Session.ID == oldID {
+			m.sesList.Select(i)
+			return
+		}
 	}
+	skipHeaders(&m.sesList, m.sesList.Index())
+}
+
+// togglePin pins or unpins the selection in the focused column and saves the
+// pins right away.
+func (m *model) togglePin() {
+	if m.pins == nil {
+		return
+	}
+	switch m.focus {
+	case focusProjects:
+		p := m.selectedProject()
+		if p == nil {
+			return
+		}
+		m.pins.ToggleProject(p.Worktree)
+		m.applyProjectFilter(false)
+	case focusSessions:
+		if id := m.selectedSessionID(); id != "" {
+			m.toggleSessionPin(id)
+		}
+		return
+	default:
+		return
+	}
+	m.pinErr = ""
+	if err := m.pins.Save(); err != nil {
+		m.pinErr = err.Error()
+	}
+}
+
+// toggleSessionPin pins or unpins a session, saves the pins right away and
+// refreshes the lists showing it.
+func (m *model) toggleSessionPin(id string) {
+	if m.pins == nil {
+		return
+	}
+	m.pins.ToggleSession(id)
+	m.pinErr = ""
+	if err := m.pins.Save(); err != nil {
+		m.pinErr = err.Error()
+	}
+	m.refreshSession(id)
+}
+
+// saveStatus reports a failure to save pins or notes in the help line.
+func (m model) saveStatus() string {
+	switch {
+	case m.pinErr != "":
+		return "pins not saved: " + m.pinErr
+	case m.noteErr != "":
+		return "notes not saved: " + m.noteErr
+	}
+	return ""
+}
+
+func (m model) selectedProjectID() string {
+	if p := m.selectedProject(); p != nil {
+		return p.ID
+	}
+	return ""
 }
 
 func (m model) selectedProject() *opencodestorage.Project {
@@ -1495,11 +2197,152 @@ func (m model) selectedModel() config.Model {
 	if it == nil {
 		return m.models[m.defaultModelIdx]
 	}
-	mi, ok := it.(modelItem)
-	if !ok {
+	switch mi := it.(type) {
+	case modelItem:
+		return mi.Model
+	case profileItem:
+		return mi.Resolved
+	default:
 		return m.models[m.defaultModelIdx]
 	}
-	return mi.Model
+}
+
+// selectedProfile returns the profile picked in the model column, if any.
+func (m model) selectedProfile() *config.Profile {
+	pi, ok := m.modelList.SelectedItem().(profileItem)
+	if !ok {
+		return nil
+	}
+	p := pi.Profile
+	return &p
+}
+
+type projectModelsResult struct {
+	models config.ProjectModels
+	err    error
+}
+
+// modelsForWorktree returns the models that apply to a project. Results are
+// cached per worktree since resolving may read project-local config files.
+func (m model) modelsForWorktree(worktree string) (config.ProjectModels, error) {
+	if m.projectModels == nil || strings.TrimSpace(worktree) == "" {
+		return config.ProjectModels{Models: m.models, Default: m.models[m.defaultModelIdx]}, nil
+	}
+	if r, ok := m.projectCache[worktree]; ok {
+		return r.models, r.err
+	}
+	pm, err := m.projectModels(worktree)
+	if m.projectCache != nil {
+		m.projectCache[worktree] = projectModelsResult{models: pm, err: err}
+	}
+	return pm, err
+}
+
+// launchSelection picks the model and profile for launches that bypass the
+// model column (recent sessions, search). The selection comes from another
+// project's column, so it is resolved against the models and profiles of the
+// session's own worktree: a profile is kept when that project offers it, the
+// preferred model when the project allows it, and the project's default model
+// is used otherwise.
+func (m model) launchSelection(worktree string, preferred config.Model, profile *config.Profile) (config.Model, *config.Profile) {
+	pm, _ := m.modelsForWorktree(worktree)
+	if profile != nil {
+		for _, p := range pm.Profiles {
+			if p.Name == profile.Name {
+				return p.Resolved, &p
+			}
+		}
+	}
+	if len(pm.Models) == 0 || hasModel(pm.Models, preferred) {
+		return preferred, nil
+	}
+	return pm.Default, nil
+}
+
+func hasModel(models []config.Model, mdl config.Model) bool {
+	for _, x := range models {
+		if x == mdl {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProjectModels swaps the model column to the models that apply to the
+// selected project. The selection only resets to the project's default when
+// the applicable models change, so a manual pick survives moving between
+// projects that share the same rules.
+func (m *model) applyProjectModels() {
+	worktree := ""
+	if p := m.selectedProject(); p != nil {
+		worktree = p.Worktree
+	}
+	pm, err := m.modelsForWorktree(worktree)
+	hadErr := m.modelErr
+	m.modelErr = ""
+	if err != nil {
+		m.modelErr = err.Error()
+	}
+	if m.modelErr != hadErr {
+		m.resize()
+	}
+	if len(pm.Models) == 0 || sameProjectModels(pm, m.shownModels) {
+		return
+	}
+	m.shownModels = pm
+	m.setModelItems(nil)
+}
+
+// setModelItems fills the model column from shownModels. keep is the item
+// to keep selected; nil selects the project's default model.
+func (m *model) setModelItems(keep list.Item) {
+	pm := m.shownModels
+	models := pm.Models
+	if m.frecentModels {
+		models = m.sortModelsByFrecency(models)
+	}
+
+	// Profiles come first; they are the richer launch choice.
+	items := make([]list.Item, 0, len(pm.Profiles)+len(models))
+	for _, p := range pm.Profiles {
+		items = append(items, profileItem{p})
+	}
+	idx := 0
+	for i, mdl := range models {
+		items = append(items, modelItem{mdl})
+		if keep == nil && mdl == pm.Default {
+			idx = len(pm.Profiles) + i
+		}
+	}
+	if keep != nil {
+		idx = m.modelList.Index()
+		if mi, ok := keep.(modelItem); ok {
+			for i, it := range items {
+				if other, ok := it.(modelItem); ok && other == mi {
+					idx = i
+				}
+			}
+		}
+	}
+	m.modelList.SetItems(items)
+	m.modelList.Select(idx)
+}
+
+func sameProjectModels(a, b config.ProjectModels) bool {
+	if a.Default != b.Default || len(a.Models) != len(b.Models) || len(a.Profiles) != len(b.Profiles) {
+		return false
+	}
+	for i := range a.Models {
+		if a.Models[i] != b.Models[i] {
+			return false
+		}
+	}
+	for i := range a.Profiles {
+		if a.Profiles[i].Name != b.Profiles[i].Name || a.Profiles[i].Resolved != b.Profiles[i].Resolved {
+			return false
+		}
+	}
+	return true
 }
 
 func (m model) selectedSessionID() string {
@@ -1552,33 +2395,120 @@ func (m *model) setPlanFromSelection() bool {
 		ProjectDir: p.Worktree,
 		Model:      m.selectedModel(),
 		SessionID:  m.selectedSessionID(),
+		Profile:    m.selectedProfile(),
 	}
 	return true
 }
 
-type projectItem struct{ opencodestorage.Project }
+type projectItem struct {
+	opencodestorage.Project
+	display config.ProjectDisplay
+	pinned  bool
+	matched []int
+}
+
+func (m model) newProjectItem(p opencodestorage.Project) projectItem {
+	return projectItem{Project: p, display: m.projectDisplay[p.ID], pinned: m.pins.ProjectPinned(p.Worktree)}
+}
+
+// pinMarker prefixes the titles of pinned projects and sessions.
+const pinMarker = "★ "
 
 func (p projectItem) Title() string {
+	name := p.display.Name
 	if isGlobalProject(p.Project) {
-		return "General"
+		name = "General"
+	} else if name == "" {
+		name = filepath.Base(p.Worktree)
 	}
-	return filepath.Base(p.Worktree)
+	if p.display.Icon != "" {
+		name = p.display.Icon + " " + name
+	}
+	if p.pinned {
+		return pinMarker + name
+	}
+	return name
 }
 
 func (p projectItem) Description() string {
 	if isGlobalProject(p.Project) {
 		return "Sessions outside a Git repo"
 	}
+	if p.display.Tag != "" {
+		return "[" + p.display.Tag + "] " + shortenPath(p.Worktree, maxProjectDescLen-len(p.display.Tag)-3)
+	}
 	return shortenPath(p.Worktree, maxProjectDescLen)
 }
-func (p projectItem) FilterValue() string { return p.Title() + " " + p.Description() }
+
+func (p projectItem) FilterValue() string {
+	return strings.Join(append([]string{p.Title(), p.Description()}, p.display.Aliases...), " ")
+}
+
+func (p projectItem) matchedRunes() []int { return p.matched }
+
+// match matches the lowercased query q against the project's name, path,
+// tag and aliases.
+func (p projectItem) match(mt matcher, q string) (score int, titleRunes []int, ok bool) {
+	if mt.substring {
+		if !p.matches(q) {
+			return 0, nil, false
+		}
+		return 0, substringRunes(p.Title(), q), true
+	}
+	return mt.match(q, append([]string{p.Title(), p.Description()}, p.display.Aliases...)...)
+}
+
+// matches reports whether the lowercased query q is a substring of the
+// project's name, path or tag, or a prefix of an alias.
+func (p projectItem) matches(q string) bool {
+	if strings.Contains(strings.ToLower(p.Title()), q) || strings.Contains(strings.ToLower(p.Description()), q) {
+		return true
+	}
+	for _, a := range p.display.Aliases {
+		if strings.HasPrefix(strings.ToLower(a), q) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p projectItem) hasAlias(q string) bool {
+	for _, a := range p.display.Aliases {
+		if strings.ToLower(strings.TrimSpace(a)) == q {
+			return true
+		}
+	}
+	return false
+}
 
 type modelItem struct{ config.Model }
 
-func (mi modelItem) Title() string       { return mi.Name }
-func (mi modelItem) Description() string { return mi.Model.Model }
+// discoveredSuffix marks models that come from OpenCode's config only.
+const discoveredSuffix = "  (discovered)"
+
+func (mi modelItem) Title() string { return mi.Name }
+func (mi modelItem) Description() string {
+	if mi.Discovered {
+		return mi.Model.Model + discoveredSuffix
+	}
+	return mi.Model.Model
+}
 func (mi modelItem) FilterValue() string { return mi.Name + " " + mi.Model.Model }
 
+type profileItem struct{ config.Profile }
+
+func (pi profileItem) Title() string { return pi.Name }
+
+func (pi profileItem) Description() string {
+	parts := []string{"profile", pi.Resolved.Name}
+	if a := strings.TrimSpace(pi.Agent); a != "" {
+		parts = append(parts, "agent "+a)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (pi profileItem) FilterValue() string { return pi.Name + " " + pi.Description() }
+
 type sessionNewItem struct{}
 
 func (s sessionNewItem) Title() string       { return "New session (choose model)" }
@@ -1588,23 +2518,32 @@ func (s sessionNewItem) FilterValue() string { return "new" }
 type sessionItem struct {
 	Session opencodestorage.Session
 	showDir bool
+	pinned  bool
+	note    state.Note
+	matched []int
 }
 
-func (s sessionItem) Title() string { return s.Session.Title }
+func (s sessionItem) matchedRunes() []int { return s.matched }
+
+func (s sessionItem) Title() string {
+	if s.pinned {
+		return pinMarker + s.Session.Title
+	}
+	return s.Session.Title
+}
 
 func (s sessionItem) Description() string {
-	updated := formatUpdated(s.Session.Updated)
-	if !s.showDir {
-		return updated
+	parts := make([]string, 0, 3)
+	if updated := formatUpdated(s.Session.Updated); updated != "" {
+		parts = append(parts, updated)
 	}
-	dir := shortenPath(s.Session.Directory, maxSessionDescLen)
-	if updated == "" {
-		return dir
+	if dir := shortenPath(s.Session.Directory, maxSessionDescLen); s.showDir && dir != "" {
+		parts = append(parts, dir)
 	}
-	if dir == "" {
-		return updated
+	if note := noteSuffix(s.note); note != "" {
+		parts = append(parts, note)
 	}
-	return updated + "  " + dir
+	return strings.Join(parts, "  ")
 }
 
 func (s sessionItem) FilterValue() string { return s.Title() + " " + s.Description() }
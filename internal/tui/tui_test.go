@@ -1,10 +1,25 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+
+	"oc/internal/config"
 	"oc/internal/opencodestorage"
+	"oc/internal/state"
 )
 
 func TestChooseLayoutMode_BreakpointBoundary(t *testing.T) {
@@ -85,3 +100,1200 @@ func TestSessionItemDescription_HidesDirectoryForNonGlobal(t *testing.T) {
 		t.Fatalf("expected non-global description to not include directory, got %q", desc)
 	}
 }
+
+func TestSessionSearchItemDescription_ShowsMatchCount(t *testing.T) {
+	it := sessionSearchItem{
+		res: opencodestorage.SessionSearchResult{
+			Session:    opencodestorage.Session{Title: "hello", Updated: 1000},
+			MatchText:  "we talked about widgets",
+			MatchCount: 4,
+		},
+		queryLC: "widgets",
+	}
+	if desc := it.Description(); !strings.Contains(desc, "4 matches") {
+		t.Fatalf("expected match count in description, got %q", desc)
+	}
+}
+
+func TestExcerptMatch_IsRuneSafeAndCentersOnTerms(t *testing.T) {
+	text := strings.Repeat("é", 60) + " the Widget überall " + strings.Repeat("ü", 60)
+	got := excerptMatch(text, "widget", 40)
+	if !utf8.ValidString(got) || !strings.Contains(got, "Widget") || len([]rune(got)) > 40 {
+		t.Fatalf("expected a valid excerpt around the match, got %q", got)
+	}
+	// Best-match queries need not appear as a phrase; the first term found
+	// centers the excerpt.
+	if got := excerptMatch(text, "überall widget", 40); !strings.Contains(got, "Widget") {
+		t.Fatalf("expected the excerpt to center on a term, got %q", got)
+	}
+	if got := truncatePlain("日本語のテキスト", 6); got != "日本語..." {
+		t.Fatalf("expected rune-based truncation, got %q", got)
+	}
+}
+
+func TestSearchDelegate_HighlightsEveryOccurrence(t *testing.T) {
+	it := sessionSearchItem{
+		res: opencodestorage.SessionSearchResult{
+			Session:   opencodestorage.Session{Title: "Größe of widgets", Updated: 1000},
+			MatchText: "widgets and more WIDGETS",
+		},
+		queryLC: "widgets größe",
+	}
+	title := []rune(it.Title())
+	var got []string
+	for _, i := range it.matchedRunes() {
+		got = append(got, string(title[i]))
+	}
+	if strings.Join(got, "") != "Größewidgets" {
+		t.Fatalf("expected both terms highlighted in the title, got %v", got)
+	}
+	desc := []rune(it.Description())
+	got = nil
+	for _, i := range it.matchedDescRunes() {
+		got = append(got, string(desc[i]))
+	}
+	if strings.Join(got, "") != "widgetsWIDGETS" {
+		t.Fatalf("expected every occurrence in the snippet, got %v", got)
+	}
+
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(prev)
+	m := newModel(Input{Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}}})
+	m.width, m.height = 160, 40
+	m.resize()
+	m.searchList.SetItems([]list.Item{it})
+	d := newSearchDelegate(newTheme(config.Theme{}, false))
+	var highlighted strings.Builder
+	d.Render(&highlighted, m.searchList, 1, it)
+	lines := strings.Split(highlighted.String(), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "\x1b[1") {
+		t.Fatalf("expected bold matches in the description:\n%q", highlighted.String())
+	}
+}
+
+func TestKeyMap_RebindingDrivesUpdateAndHelp(t *testing.T) {
+	m := newModel(Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Keys:   map[string][]string{"search": {"ctrl+k"}, "recent": {}},
+	})
+	m.width, m.height = 160, 40
+	m.resize()
+
+	header := ansi.Strip(m.View())
+	if !strings.Contains(header, "ctrl+k: global search") || strings.Contains(header, "ctrl+f") {
+		t.Fatalf("expected help line to show rebound search key, got:\n%s", header)
+	}
+	if strings.Contains(header, "recent") {
+		t.Fatalf("expected unbound recent action to be hidden, got:\n%s", header)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if next.(model).searchOpen {
+		t.Fatalf("expected ctrl+f to no longer open search")
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if !next.(model).searchOpen {
+		t.Fatalf("expected ctrl+k to open search")
+	}
+}
+
+func TestNewTheme_OverridesAndNoColor(t *testing.T) {
+	th := newTheme(config.Theme{Name: "light", Roles: map[string]string{"accent": "#123456"}}, false)
+	if th.accent != lipgloss.Color("#123456") || th.border != builtinThemes["light"].border {
+		t.Fatalf("expected light theme with accent override, got %+v", th)
+	}
+
+	m := newModel(Input{
+		Models:  []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Theme:   config.Theme{Name: "dark"},
+		NoColor: true,
+	})
+	if _, ok := m.styles.panelActive.GetBorderTopForeground().(lipgloss.NoColor); !ok {
+		t.Fatalf("expected uncolored borders with NoColor")
+	}
+	if m.styles.panelActive.GetBorderStyle() != lipgloss.ThickBorder() {
+		t.Fatalf("expected focused panel to use a thick border without color")
+	}
+	d := newDelegate(newTheme(config.Theme{}, true))
+	if _, ok := d.Styles.SelectedTitle.GetForeground().(lipgloss.NoColor); !ok {
+		t.Fatalf("expected uncolored list delegate with NoColor")
+	}
+}
+
+func TestProjectDisplay_TitlesFilterAndHide(t *testing.T) {
+	m := newModel(Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/acme/api"},
+			{ID: "p2", Worktree: "/src/globex/api"},
+			{ID: "p3", Worktree: "/tmp/clone/api"},
+		},
+		ProjectDisplay: func(worktree string) config.ProjectDisplay {
+			switch worktree {
+			case "/src/acme/api":
+				return config.ProjectDisplay{Name: "acme-api", Aliases: []string{"aa"}, Tag: "client"}
+			case "/tmp/clone/api":
+				return config.ProjectDisplay{Hide: true}
+			}
+			return config.ProjectDisplay{}
+		},
+	})
+
+	var titles []string
+	for _, it := range m.projList.Items() {
+		titles = append(titles, it.(projectItem).Title())
+	}
+	if strings.Join(titles, ",") != "acme-api,api" {
+		t.Fatalf("expected renamed project and hidden clone, got %v", titles)
+	}
+	if d := m.projList.Items()[0].(projectItem).Description(); !strings.HasPrefix(d, "[client] ") {
+		t.Fatalf("expected tag in description, got %q", d)
+	}
+
+	m.projFilter.SetValue("aa")
+	m.applyProjectFilter(true)
+	if items := m.projList.Items(); len(items) != 1 || items[0].(projectItem).ID != "p1" {
+		t.Fatalf("expected alias to match acme only, got %d items", len(items))
+	}
+}
+
+func TestSessionLimits_HideAndReveal(t *testing.T) {
+	now := time.Now()
+	m := newModel(Input{
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+		SessionLimits: func(string) config.SessionLimits {
+			return config.SessionLimits{MaxAgeDays: 7, MaxCount: 2, MinMessages: 1}
+		},
+	})
+	m.sessionsByProject["p1"] = []opencodestorage.Session{
+		{ID: "empty", Updated: now.UnixMilli(), Messages: 0},
+		{ID: "a", Updated: now.Add(-time.Hour).UnixMilli(), Messages: 3},
+		{ID: "unknown", Updated: now.Add(-2 * time.Hour).UnixMilli(), Messages: -1},
+		{ID: "b", Updated: now.Add(-3 * time.Hour).UnixMilli(), Messages: 5},
+		{ID: "old", Updated: now.Add(-30 * 24 * time.Hour).UnixMilli(), Messages: 5},
+	}
+	m.applySessionFilter(true)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	m = next.(model)
+
+	ids := func() string {
+		var out []string
+		for _, it := range m.sesList.Items() {
+			if si, ok := it.(sessionItem); ok {
+				out = append(out, si.Session.ID)
+			}
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(); got != "a,unknown" || m.sesHidden != 3 {
+		t.Fatalf("expected a,unknown with 3 hidden, got %q (%d hidden)", got, m.sesHidden)
+	}
+	if v := ansi.Strip(m.View()); !strings.Contains(v, "Sessions (3 hidden)") || !strings.Contains(v, "ctrl+t: show hidden") {
+		t.Fatalf("expected hidden indicator and help:\n%s", v)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = next.(model)
+	if got := ids(); got != "empty,a,unknown,b,old" {
+		t.Fatalf("expected every session after reveal, got %q", got)
+	}
+}
+
+func TestSessionLimits_ExplicitZeroMaxAgeOverridesGlobalLimit(t *testing.T) {
+	global := opencodestorage.Project{ID: "global", Worktree: "/"}
+	limits := config.SessionLimits{}
+	m := newModel(Input{
+		Models:                   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		GlobalSessionsMaxAgeDays: 7,
+		SessionLimits:            func(string) config.SessionLimits { return limits },
+	})
+	if got := m.sessionLimitsFor(global); got.MaxAgeDays != 7 {
+		t.Fatalf("expected the global limit to fill an unset max age, got %+v", got)
+	}
+	limits = config.SessionLimits{MaxAgeSet: true}
+	if got := m.sessionLimitsFor(global); got.MaxAgeDays != 0 || !got.IsZero() {
+		t.Fatalf("expected max_age_days: 0 to turn the age limit off, got %+v", got)
+	}
+}
+
+func TestPins_GroupOnTopBypassPolicyAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	pins, err := state.LoadPins(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins.ToggleSession("old")
+	m := newModel(Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/new"},
+			{ID: "p2", Worktree: "/src/daily"},
+		},
+		SessionLimits: func(string) config.SessionLimits { return config.SessionLimits{MaxCount: 1} },
+		Pins:          pins,
+	})
+
+	// Pin the second project; it moves to the top and stays selected.
+	m.projList.Select(1)
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p"), Alt: true})
+	m = next.(model)
+	if first := m.projList.Items()[0].(projectItem); first.ID != "p2" || first.Title() != "★ daily" {
+		t.Fatalf("expected pinned project first, got %q", first.Title())
+	}
+	if p := m.selectedProject(); p == nil || p.ID != "p2" {
+		t.Fatalf("expected pinned project to stay selected")
+	}
+	reloaded, err := state.LoadPins(dir)
+	if err != nil || !reloaded.ProjectPinned("/src/daily") {
+		t.Fatalf("expected pin to be saved, got %+v (%v)", reloaded, err)
+	}
+
+	now := time.Now()
+	m.sessionsByProject["p2"] = []opencodestorage.Session{
+		{ID: "a", Title: "a", Updated: now.UnixMilli()},
+		{ID: "b", Title: "b", Updated: now.Add(-time.Hour).UnixMilli()},
+		{ID: "old", Title: "old", Updated: now.Add(-90 * 24 * time.Hour).UnixMilli()},
+	}
+	m.applySessionFilter(true)
+	var titles []string
+	for _, it := range m.sesList.Items()[1:] {
+		if si, ok := it.(sessionItem); ok {
+			titles = append(titles, si.Title())
+		}
+	}
+	if strings.Join(titles, ",") != "★ old,a" || m.sesHidden != 1 {
+		t.Fatalf("expected pinned session first and exempt from max_count, got %v (%d hidden)", titles, m.sesHidden)
+	}
+}
+
+func TestFrecencySort_TogglesProjectsAndModels(t *testing.T) {
+	now := time.Now()
+	m := newModel(Input{
+		Models: []config.Model{
+			{Name: "GPT", Model: "openai/gpt-5.2"},
+			{Name: "Claude", Model: "anthropic/claude"},
+		},
+		Projects: []opencodestorage.Project{
+			{ID: "global", Worktree: "/"},
+			{ID: "p1", Worktree: "/src/busy"},
+			{ID: "p2", Worktree: "/src/daily"},
+		},
+		Launches: []state.Launch{
+			{Time: now.Add(-time.Hour), Project: "/src/daily", Model: "anthropic/claude"},
+			{Time: now.Add(-2 * time.Hour), Project: "/src/daily", Model: "anthropic/claude"},
+		},
+		Sort: config.Sort{Models: config.SortFrecency},
+	})
+	projectIDs := func() string {
+		var ids []string
+		for _, it := range m.projList.Items() {
+			ids = append(ids, it.(projectItem).ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	if got := projectIDs(); got != "global,p1,p2" {
+		t.Fatalf("expected recency order by default, got %s", got)
+	}
+	if first := m.modelList.Items()[0].(modelItem); first.Name != "Claude" {
+		t.Fatalf("expected frecent model first from config, got %s", first.Name)
+	}
+	if m.selectedModel().Name != "GPT" {
+		t.Fatalf("expected the default model to stay selected, got %s", m.selectedModel().Name)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = next.(model)
+	if got := projectIDs(); got != "global,p2,p1" {
+		t.Fatalf("expected frecent project after General, got %s", got)
+	}
+	if p := m.selectedProject(); p == nil || p.ID != "p1" {
+		t.Fatalf("expected selection to survive the sort toggle")
+	}
+
+	m.focus = focusModels
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = next.(model)
+	if first := m.modelList.Items()[0].(modelItem); first.Name != "GPT" || m.selectedModel().Name != "GPT" {
+		t.Fatalf("expected config order with selection kept, got %s first", first.Name)
+	}
+}
+
+func TestFuzzyFilter_RanksAndHighlights(t *testing.T) {
+	in := Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/tools/color-lab"},
+			{ID: "p2", Worktree: "/src/opencode-launcher"},
+		},
+	}
+	m := newModel(in)
+	m.projFilter.SetValue("ocl")
+	m.applyProjectFilter(true)
+	items := m.projList.Items()
+	if len(items) != 2 || items[0].(projectItem).ID != "p2" {
+		t.Fatalf("expected opencode-launcher ranked first, got %d items", len(items))
+	}
+	pi := items[0].(projectItem)
+	var got []string
+	runes := []rune(pi.Title())
+	for _, i := range pi.matchedRunes() {
+		got = append(got, string(runes[i]))
+	}
+	if strings.Join(got, "") != "ocl" {
+		t.Fatalf("expected matched runes to spell the query, got %v", got)
+	}
+
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(prev)
+	m.width, m.height = 160, 40
+	m.resize()
+	d := newDelegate(newTheme(config.Theme{}, false))
+	var plain, highlighted strings.Builder
+	d.Render(&highlighted, m.projList, 1, pi)
+	pi.matched = nil
+	d.Render(&plain, m.projList, 1, pi)
+	if highlighted.String() == plain.String() || ansi.Strip(highlighted.String()) != ansi.Strip(plain.String()) {
+		t.Fatalf("expected highlighting to only add styling:\n%q\n%q", highlighted.String(), plain.String())
+	}
+
+	in.SubstringFilter = true
+	m = newModel(in)
+	m.projFilter.SetValue("ocl")
+	m.applyProjectFilter(true)
+	if n := len(m.projList.Items()); n != 0 {
+		t.Fatalf("expected no substring matches for ocl, got %d", n)
+	}
+	m.projFilter.SetValue("launch")
+	m.applyProjectFilter(true)
+	if items := m.projList.Items(); len(items) != 1 || strings.Join(intsToStrings(items[0].(projectItem).matchedRunes()), ",") != "9,10,11,12,13,14" {
+		t.Fatalf("expected substring highlight, got %+v", items)
+	}
+}
+
+func TestRuneIndexes_ConvertsByteOffsets(t *testing.T) {
+	s := "★ api"
+	if got := runeIndexes(s, []int{4, 5}); got[0] != 2 || got[1] != 3 {
+		t.Fatalf("expected rune indexes 2,3, got %v", got)
+	}
+}
+
+func intsToStrings(xs []int) []string {
+	out := make([]string, len(xs))
+	for i, x := range xs {
+		out[i] = strconv.Itoa(x)
+	}
+	return out
+}
+
+func TestMouse_ClickSelectsDoubleClickLaunchesWheelScrolls(t *testing.T) {
+	in := Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/api"},
+			{ID: "p2", Worktree: "/src/web"},
+			{ID: "p3", Worktree: "/src/cli"},
+		},
+	}
+	click := func(m model, x, y int) (model, tea.Cmd) {
+		next, cmd := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+		return next.(model), cmd
+	}
+	sized := func(in Input) model {
+		next, _ := newModel(in).Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+		return next.(model)
+	}
+
+	// Without ui.mouse, clicks are ignored.
+	m, _ := click(sized(in), 2, 8)
+	if p := m.selectedProject(); p == nil || p.ID != "p1" {
+		t.Fatalf("expected clicks to be ignored without mouse support")
+	}
+
+	in.Mouse = true
+	m = sized(in)
+	// Project items start below the border, title and filter line and take
+	// three rows each.
+	m, _ = click(m, 2, 8)
+	if p := m.selectedProject(); p == nil || p.ID != "p2" {
+		t.Fatalf("expected click to select the second project")
+	}
+
+	next, _ := m.Update(tea.MouseMsg{X: 2, Y: 8, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	m = next.(model)
+	if p := m.selectedProject(); p == nil || p.ID != "p3" {
+		t.Fatalf("expected wheel to move to the third project")
+	}
+
+	m.sessionsByProject["p3"] = []opencodestorage.Session{{ID: "s1", Title: "fix", Updated: time.Now().UnixMilli()}}
+	m.applySessionFilter(true)
+	// Below "New session" and the "Today" header.
+	sesX := outerMarginLeft + m.colWProj + 2 + colGapSpaces + 1
+	m, _ = click(m, sesX, 11)
+	if m.focus != focusSessions || m.selectedSessionID() != "s1" {
+		t.Fatalf("expected click to focus sessions and select s1, got focus %v session %q", m.focus, m.selectedSessionID())
+	}
+	if m.plan != nil {
+		t.Fatalf("expected a single click not to launch")
+	}
+	m, cmd := click(m, sesX, 11)
+	if m.plan == nil || m.plan.SessionID != "s1" || m.plan.ProjectDir != "/src/cli" || cmd == nil {
+		t.Fatalf("expected double-click to launch s1, got %+v", m.plan)
+	}
+}
+
+func TestNotes_EditTagsAndFilterByTag(t *testing.T) {
+	dir := t.TempDir()
+	notes, err := state.LoadNotes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(Input{
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+		Notes:    notes,
+	})
+	now := time.Now()
+	m.sessionsByProject["p1"] = []opencodestorage.Session{
+		{ID: "s1", Title: "fix login", Updated: now.UnixMilli()},
+		{ID: "s2", Title: "refactor", Updated: now.Add(-time.Hour).UnixMilli()},
+	}
+	m.applySessionFilter(true)
+	m.focus = focusSessions
+	m.updateFocus()
+	for i, it := range m.sesList.Items() {
+		if si, ok := it.(sessionItem); ok && si.Session.ID == "s2" {
+			m.sesList.Select(i)
+		}
+	}
+
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	typeText := func(s string) tea.Msg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e"), Alt: true})
+	if !m.editor.open || m.editor.sessionID != "s2" {
+		t.Fatalf("expected the editor to open for s2")
+	}
+	send(typeText("#Spike ticket-123"), tea.KeyMsg{Type: tea.KeyTab}, typeText("needs review"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.editor.open || m.plan != nil {
+		t.Fatalf("expected enter to save and close the editor without launching")
+	}
+	if si := m.sesList.SelectedItem().(sessionItem); si.Session.ID != "s2" || !strings.HasSuffix(si.Description(), "#spike #ticket-123 needs review") {
+		t.Fatalf("expected the note in the description, got %q", si.Description())
+	}
+	reloaded, err := state.LoadNotes(dir)
+	if err != nil || strings.Join(reloaded.Get("s2").Tags, ",") != "spike,ticket-123" {
+		t.Fatalf("expected the note to be saved, got %+v (%v)", reloaded.Get("s2"), err)
+	}
+
+	send(typeText("#tick"))
+	var ids []string
+	for _, it := range m.sesList.Items() {
+		if si, ok := it.(sessionItem); ok {
+			ids = append(ids, si.Session.ID)
+		}
+	}
+	if strings.Join(ids, ",") != "s2" {
+		t.Fatalf("expected #tick to keep only the tagged session, got %v", ids)
+	}
+}
+
+func TestFilter_KeepsTextinputCursorKeys(t *testing.T) {
+	m := newModel(Input{
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+	})
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	send(tea.WindowSizeMsg{Width: 160, Height: 30}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("api")})
+	send(tea.KeyMsg{Type: tea.KeyCtrlB}, tea.KeyMsg{Type: tea.KeyCtrlB})
+	if m.projFilter.Position() != 1 || m.pins.ProjectPinned("/src/api") {
+		t.Fatalf("expected ctrl+b to move the cursor back, got %d", m.projFilter.Position())
+	}
+	send(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if m.projFilter.Position() != 3 || m.editor.open {
+		t.Fatalf("expected ctrl+e to move the cursor to the end, got %d", m.projFilter.Position())
+	}
+}
+
+func TestHelpOverlay_ListsBindingsScrollsAndCloses(t *testing.T) {
+	m := newModel(Input{
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+		Keys:     map[string][]string{"pin": {"ctrl+b", "alt+p"}},
+	})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 14})
+	m = next.(model)
+	send := func(msg tea.Msg) {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	question := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	// With text in the filter, "?" is typed.
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	send(question)
+	if m.help.open || m.projFilter.Value() != "a?" {
+		t.Fatalf("expected ? to be typed into a non-empty filter, got %q", m.projFilter.Value())
+	}
+	m.projFilter.SetValue("")
+
+	send(question)
+	if !m.help.open {
+		t.Fatalf("expected ? to open the help overlay")
+	}
+	if v := ansi.Strip(m.View()); !strings.Contains(v, "Help: projects") || !strings.Contains(v, "Columns") {
+		t.Fatalf("expected grouped help for the projects column:\n%s", v)
+	}
+	if lines := ansi.Strip(strings.Join(m.helpLines(), "\n")); !strings.Contains(lines, "ctrl+b / alt+p") {
+		t.Fatalf("expected every key bound to pin:\n%s", lines)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyEnd})
+	if m.help.offset == 0 {
+		t.Fatalf("expected the overlay to scroll in a short terminal")
+	}
+	if v := ansi.Strip(m.View()); !strings.Contains(v, "Views") {
+		t.Fatalf("expected the last section after scrolling to the end:\n%s", v)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.help.open || m.plan != nil {
+		t.Fatalf("expected esc to close the overlay")
+	}
+}
+
+func TestDateGroup_Buckets(t *testing.T) {
+	// Thursday afternoon; the week started on Monday the 12th.
+	now := time.Date(2026, 3, 19, 15, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		at   time.Time
+		want string
+	}{
+		{now.Add(time.Hour), groupToday},
+		{time.Date(2026, 3, 19, 0, 0, 0, 0, time.UTC), groupToday},
+		{time.Date(2026, 3, 18, 23, 59, 0, 0, time.UTC), groupYesterday},
+		{time.Date(2026, 3, 16, 8, 0, 0, 0, time.UTC), groupThisWeek},
+		{time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC), groupThisMonth},
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), groupThisMonth},
+		{time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC), groupOlder},
+	} {
+		if got := dateGroup(c.at.UnixMilli(), now); got != c.want {
+			t.Fatalf("%v: expected %q, got %q", c.at, c.want, got)
+		}
+	}
+}
+
+func TestSessionGroups_SkipHeadersAndCollapseOlder(t *testing.T) {
+	now := time.Now()
+	m := newModel(Input{
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+	})
+	m.sessionsByProject["p1"] = []opencodestorage.Session{
+		{ID: "new", Title: "new", Updated: now.UnixMilli()},
+		{ID: "old1", Title: "old1", Updated: now.AddDate(0, -2, 0).UnixMilli()},
+		{ID: "old2", Title: "old2", Updated: now.AddDate(0, -3, 0).UnixMilli()},
+	}
+	m.applySessionFilter(true)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m = next.(model)
+	m.focus = focusSessions
+	m.updateFocus()
+
+	labels := func() string {
+		var out []string
+		for _, it := range m.sesList.Items()[1:] {
+			switch it := it.(type) {
+			case groupHeader:
+				out = append(out, "["+it.Description()+"]")
+			case sessionItem:
+				out = append(out, it.Session.ID)
+			}
+		}
+		return strings.Join(out, ",")
+	}
+	if got := labels(); got != "[1 session],new,[2 sessions],old1,old2" {
+		t.Fatalf("unexpected groups: %s", got)
+	}
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for _, want := range []string{"new", "old1"} {
+		next, _ = m.Update(down)
+		m = next.(model)
+		if got := m.selectedSessionID(); got != want {
+			t.Fatalf("expected down to skip headers to %s, got %q", want, got)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = next.(model)
+	if got := labels(); got != "[1 session],new,[2 sessions (collapsed)]" {
+		t.Fatalf("expected older groups to collapse, got %s", got)
+	}
+	if _, ok := m.sesList.SelectedItem().(groupHeader); ok {
+		t.Fatalf("expected the selection to leave the collapsed group")
+	}
+	if v := ansi.Strip(m.View()); !strings.Contains(v, "ctrl+g: expand older") {
+		t.Fatalf("expected the expand binding in the help line:\n%s", v)
+	}
+}
+
+// pagedStore serves recent sessions page by page. With short set, pages
+// leave out their last session, as a composite store's pages can after it
+// drops duplicates.
+type pagedStore struct {
+	opencodestorage.Store
+	recent []opencodestorage.SessionSearchResult // newest first
+	pages  int
+	short  bool
+}
+
+func (s *pagedStore) RecentSessionsBefore(_ context.Context, cursor opencodestorage.RecentCursor, limit int) ([]opencodestorage.SessionSearchResult, bool, error) {
+	s.pages++
+	if s.short {
+		limit--
+	}
+	var out []opencodestorage.SessionSearchResult
+	for _, r := range s.recent {
+		if !cursor.IsZero() && r.Session.Updated >= cursor.Updated {
+			continue
+		}
+		if len(out) == limit {
+			return out, true, nil
+		}
+		out = append(out, r)
+	}
+	return out, false, nil
+}
+
+// drain runs cmd and feeds the messages it produces back into m.
+func drain(m model, cmd tea.Cmd) model {
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = drain(m, c)
+		}
+	case recentSessionsLoadedMsg:
+		next, cmd := m.Update(msg)
+		m = drain(next.(model), cmd)
+	}
+	return m
+}
+
+func TestRecent_PagesFiltersAndGroupsByProject(t *testing.T) {
+	now := time.Now()
+	store := &pagedStore{}
+	for i := 0; i < 150; i++ {
+		pid := "p1"
+		if i%2 == 1 {
+			pid = "p2"
+		}
+		store.recent = append(store.recent, opencodestorage.SessionSearchResult{
+			ProjectID:       pid,
+			ProjectWorktree: "/src/" + pid,
+			Session:         opencodestorage.Session{ID: "s" + strconv.Itoa(i), Title: "session " + strconv.Itoa(i), Updated: now.Add(-time.Duration(i) * time.Minute).UnixMilli()},
+		})
+	}
+	m := newModel(Input{
+		Store:           store,
+		Models:          []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		RecentByProject: true,
+	})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	m = next.(model)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = drain(next.(model), cmd)
+	if len(m.recentAll) != recentPageSize || store.pages != 1 {
+		t.Fatalf("expected one page of %d, got %d sessions in %d pages", recentPageSize, len(m.recentAll), store.pages)
+	}
+	if h, ok := m.recentList.Items()[0].(groupHeader); !ok || h.label != "p1" || h.count != 50 {
+		t.Fatalf("expected a p1 project header first, got %#v", m.recentList.Items()[0])
+	}
+
+	// Scrolling to the end loads the rest.
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	m = drain(next.(model), cmd)
+	if len(m.recentAll) != 150 || m.recentMore {
+		t.Fatalf("expected all 150 sessions and no more pages, got %d (more=%v)", len(m.recentAll), m.recentMore)
+	}
+
+	// Short pages don't stop the paging while the store reports more.
+	store.short, store.pages = true, 0
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(model)
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = drain(next.(model), cmd)
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	m = drain(next.(model), cmd)
+	if len(m.recentAll) != 150 || m.recentMore || store.pages != 2 {
+		t.Fatalf("expected all 150 sessions in 2 short pages, got %d in %d (more=%v)", len(m.recentAll), store.pages, m.recentMore)
+	}
+
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("session 149")})
+	m = drain(next.(model), cmd)
+	ri, ok := m.recentList.SelectedItem().(recentSessionItem)
+	if !ok || ri.res.Session.ID != "s149" {
+		t.Fatalf("expected the filter to select s149 first, got %#v", m.recentList.SelectedItem())
+	}
+	if _, ok := m.recentList.Items()[0].(groupHeader); ok {
+		t.Fatalf("expected a flat ranked list while filtering")
+	}
+}
+
+func TestRecent_StopsPagingWhenPagesAddNoVisibleRows(t *testing.T) {
+	now := time.Now()
+	store := &pagedStore{}
+	for i := 0; i < 5*recentPageSize; i++ {
+		store.recent = append(store.recent, opencodestorage.SessionSearchResult{
+			ProjectID:       "p1",
+			ProjectWorktree: "/src/p1",
+			Session:         opencodestorage.Session{ID: "s" + strconv.Itoa(i), Title: "session " + strconv.Itoa(i), Updated: now.Add(-time.Duration(i) * time.Minute).UnixMilli()},
+		})
+	}
+	open := func(limits config.SessionLimits) model {
+		store.pages = 0
+		m := newModel(Input{Store: store, Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}}, RecentLimits: limits})
+		next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+		next, cmd := next.(model).Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		return drain(next.(model), cmd)
+	}
+
+	m := open(config.SessionLimits{})
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("nothing matches")})
+	m = drain(next.(model), cmd)
+	if store.pages != 1 {
+		t.Fatalf("expected typing a filter not to load pages, got %d", store.pages)
+	}
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = drain(next.(model), cmd)
+	if store.pages != 2 || !m.recentMore {
+		t.Fatalf("expected scrolling to load one more page and stop, got %d (more=%v)", store.pages, m.recentMore)
+	}
+
+	m = open(config.SessionLimits{MaxCount: 5})
+	if store.pages != 2 || len(m.recentAll) != 2*recentPageSize {
+		t.Fatalf("expected max_count to stop paging after a page with no visible rows, got %d pages", store.pages)
+	}
+}
+
+func TestSearches_RecallHistorySaveAndRun(t *testing.T) {
+	dir := t.TempDir()
+	searches, err := state.LoadSearches(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searches.AddHistory("alpha", 10)
+	searches.AddHistory("beta", 10)
+	m := newModel(Input{
+		Models:        []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects:      []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}},
+		Searches:      searches,
+		SearchHistory: 10,
+	})
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	up, down := tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyDown}
+	send(tea.WindowSizeMsg{Width: 160, Height: 30}, tea.KeyMsg{Type: tea.KeyCtrlF})
+
+	var got []string
+	for _, k := range []tea.KeyMsg{up, up, up, down, down} {
+		send(k)
+		got = append(got, m.searchInput.Value())
+	}
+	if strings.Join(got, ",") != "beta,alpha,alpha,beta," {
+		t.Fatalf("unexpected recall sequence: %q", got)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("gamma")}, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !m.searchNaming {
+		t.Fatalf("expected save to prompt for a name")
+	}
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searchNaming || m.plan != nil || m.searchInput.Value() != "gamma" {
+		t.Fatalf("expected enter to save the search and keep the query")
+	}
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	reloaded, err := state.LoadSearches(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := reloaded.Find("g"); !ok || s.Query != "gamma" {
+		t.Fatalf("expected the saved search on disk, got %+v", reloaded.SavedSearches())
+	}
+	if h := strings.Join(reloaded.Recent(), ","); h != "gamma,beta,alpha" {
+		t.Fatalf("expected closing search to record the query, got %q", h)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyCtrlF}, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !m.searchPicking || !strings.Contains(ansi.Strip(m.View()), "Saved Searches") {
+		t.Fatalf("expected an empty search box to list the saved searches")
+	}
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searchPicking || m.searchInput.Value() != "gamma" || !m.searchLoading {
+		t.Fatalf("expected enter to run the saved search, got %q", m.searchInput.Value())
+	}
+
+	m = newModel(Input{Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}}, SearchQuery: "delta"})
+	if !m.searchOpen || m.searchInput.Value() != "delta" || m.Init() == nil {
+		t.Fatalf("expected SearchQuery to open search with the query running")
+	}
+}
+
+func TestSearches_ArrowsMoveThroughTheResultsOfARecalledQuery(t *testing.T) {
+	searches, err := state.LoadSearches(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	searches.AddHistory("alpha", 10)
+	searches.AddHistory("login", 10)
+	m := newModel(Input{
+		Models:        []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Searches:      searches,
+		SearchHistory: 10,
+	})
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	send(tea.WindowSizeMsg{Width: 160, Height: 30}, tea.KeyMsg{Type: tea.KeyCtrlF})
+	send(tea.MouseMsg{X: 2, Y: 8, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if m.searchInput.Value() != "" {
+		t.Fatalf("expected the wheel not to recall history, got %q", m.searchInput.Value())
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyUp})
+	if m.searchInput.Value() != "login" {
+		t.Fatalf("expected up to recall login, got %q", m.searchInput.Value())
+	}
+	var results []opencodestorage.SessionSearchResult
+	for _, id := range []string{"s1", "s2", "s3"} {
+		results = append(results, opencodestorage.SessionSearchResult{ProjectID: "p1", Session: opencodestorage.Session{ID: id, Title: "login " + id}, MatchText: "login"})
+	}
+	send(searchResultsMsg{query: "login", stage: m.searchStage, results: results})
+
+	send(tea.KeyMsg{Type: tea.KeyDown})
+	if m.searchInput.Value() != "login" || m.searchList.Index() != 1 {
+		t.Fatalf("expected down to move the selection, got query %q index %d", m.searchInput.Value(), m.searchList.Index())
+	}
+	send(tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyUp})
+	if m.searchInput.Value() != "login" || m.searchList.Index() != 0 {
+		t.Fatalf("expected up to stay in the results after moving, got query %q index %d", m.searchInput.Value(), m.searchList.Index())
+	}
+}
+
+func TestSearch_StepsSnippetsWithoutTakingTheCursorKeys(t *testing.T) {
+	m := newModel(Input{Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}}})
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	send(tea.WindowSizeMsg{Width: 160, Height: 30}, tea.KeyMsg{Type: tea.KeyCtrlF}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("login")})
+	m.searchInQuery = "login"
+	send(searchResultsMsg{query: "login", stage: m.searchStage, results: []opencodestorage.SessionSearchResult{{
+		ProjectID: "p1",
+		Session:   opencodestorage.Session{ID: "s1", Title: "fix login"},
+		MatchText: "login one",
+		Matches:   []opencodestorage.SearchMatch{{Text: "login one"}, {Text: "login two"}},
+	}}}, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.searchExpanded {
+		t.Fatalf("expected tab to expand the selected result")
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.searchMatchIdx != 0 || m.searchInput.Position() != 4 {
+		t.Fatalf("expected left to move the input cursor, got match %d cursor %d", m.searchMatchIdx, m.searchInput.Position())
+	}
+	send(tea.KeyMsg{Type: tea.KeyCtrlRight})
+	if m.searchMatchIdx != 1 {
+		t.Fatalf("expected ctrl+right to step to the next snippet, got %d", m.searchMatchIdx)
+	}
+	send(tea.KeyMsg{Type: tea.KeyCtrlLeft})
+	if m.searchMatchIdx != 0 || m.searchInput.Position() != 4 {
+		t.Fatalf("expected ctrl+left to step back without moving the cursor, got match %d cursor %d", m.searchMatchIdx, m.searchInput.Position())
+	}
+}
+
+func TestVim_NormalModeMovesFiltersAndRunsCommands(t *testing.T) {
+	dir := t.TempDir()
+	pins, _ := state.LoadPins(dir)
+	notes, _ := state.LoadNotes(dir)
+	m := newModel(Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/api"},
+			{ID: "p2", Worktree: "/src/web"},
+		},
+		Pins:  pins,
+		Notes: notes,
+		Vim:   true,
+	})
+	now := time.Now()
+	m.sessionsByProject["p1"] = []opencodestorage.Session{{ID: "s1", Title: "fix login", Updated: now.UnixMilli()}}
+	m.sessionsByProject["p2"] = []opencodestorage.Session{{ID: "s2", Title: "landing page", Updated: now.UnixMilli()}}
+	m.applySessionFilter(true)
+	send := func(keys ...string) {
+		for _, k := range keys {
+			var msg tea.KeyMsg
+			switch k {
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	m = next.(model)
+
+	send("x", "j")
+	if m.projFilter.Value() != "" || m.selectedProjectID() != "p2" {
+		t.Fatalf("expected x to be dropped and j to move down, got filter %q on %q", m.projFilter.Value(), m.selectedProjectID())
+	}
+	send("k", "/", "w", "e", "b", "esc")
+	if m.vimInsert || m.projFilter.Value() != "web" || m.selectedProjectID() != "p2" {
+		t.Fatalf("expected / to filter and esc to return to normal mode, got %q", m.projFilter.Value())
+	}
+	send("l", "j")
+	if m.focus != focusSessions || m.selectedSessionID() != "s2" {
+		t.Fatalf("expected l to focus the sessions and j to select s2, got focus %v on %q", m.focus, m.selectedSessionID())
+	}
+
+	send(":", "t", "a", "g", " ", "U", "X", "enter", ":", "p", "i", "n", "enter")
+	if n := notes.Get("s2"); strings.Join(n.Tags, ",") != "ux" || !pins.SessionPinned("s2") {
+		t.Fatalf("expected :tag and :pin to act on s2, got %+v pinned=%v", n, pins.SessionPinned("s2"))
+	}
+	out := filepath.Join(dir, "out.json")
+	send(":")
+	for _, r := range "export " + out {
+		send(string(r))
+	}
+	send("enter")
+	b, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(b), `"id": "s2"`) || !strings.Contains(m.header(nil, "x"), "exported 1 sessions") {
+		t.Fatalf("expected the export to write s2, got %s (%v)", b, err)
+	}
+	if err := os.WriteFile(out, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runCmd := func(line string) {
+		send(":")
+		for _, r := range line {
+			send(string(r))
+		}
+		send("enter")
+	}
+	runCmd("export " + out)
+	if b, _ := os.ReadFile(out); string(b) != "keep" || !strings.Contains(m.header(nil, "x"), "use :export! to overwrite") {
+		t.Fatalf("expected :export to refuse to overwrite, got %q", b)
+	}
+	runCmd("export! " + out)
+	if b, _ := os.ReadFile(out); !strings.Contains(string(b), `"id": "s2"`) {
+		t.Fatalf("expected :export! to overwrite, got %q", b)
+	}
+	stateDir := filepath.Join(dir, "state")
+	t.Setenv("OC_STATE_DIR", stateDir)
+	runCmd("export")
+	if _, err := os.Stat(filepath.Join(stateDir, defaultExportName)); err != nil {
+		t.Fatalf("expected a bare :export to write into the state directory: %v", err)
+	}
+	runCmd("export picks/today.json")
+	if _, err := os.Stat(filepath.Join(stateDir, "picks", "today.json")); err != nil {
+		t.Fatalf("expected a relative path to resolve against the state directory: %v", err)
+	}
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(home, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	runCmd("export ~/out.json")
+	if _, err := os.Stat(filepath.Join(home, "out.json")); err != nil {
+		t.Fatalf("expected ~/ to expand to the home directory: %v", err)
+	}
+
+	send("?")
+	if !m.help.open {
+		t.Fatalf("expected ? to open help in normal mode")
+	}
+	send("esc")
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = next.(model)
+	send("l", "o", "g")
+	if !m.vimInsert || m.searchInput.Value() != "log" {
+		t.Fatalf("expected search to open in insert mode, got %q", m.searchInput.Value())
+	}
+	m.searchList.SetItems([]list.Item{sessionSearchItem{res: opencodestorage.SessionSearchResult{Session: opencodestorage.Session{ID: "a"}}}, sessionSearchItem{res: opencodestorage.SessionSearchResult{Session: opencodestorage.Session{ID: "b"}}}})
+	send("esc", "n")
+	if res, _ := m.selectedSearchResult(); m.vimInsert || res.Session.ID != "b" || m.searchInput.Value() != "log" {
+		t.Fatalf("expected esc then n to step to the next result, got %q", res.Session.ID)
+	}
+	send("esc")
+	if m.searchOpen {
+		t.Fatalf("expected esc in normal mode to close search")
+	}
+}
+
+func TestInline_CompactPickerKeepsHeightAndLaunches(t *testing.T) {
+	m := newModel(Input{
+		Models: []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{
+			{ID: "p1", Worktree: "/src/api"},
+			{ID: "p2", Worktree: "/src/web"},
+			{ID: "p3", Worktree: "/src/cli"},
+			{ID: "p4", Worktree: "/src/docs"},
+		},
+		Inline:       true,
+		InlineHeight: 6,
+	})
+	m.sessionsByProject["p1"] = []opencodestorage.Session{{ID: "s1", Title: "fix login", Updated: time.Now().UnixMilli()}}
+	m.applySessionFilter(true)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = next.(model)
+
+	view := m.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected the inline picker to take 6 lines, got %d:\n%s", len(lines), view)
+	}
+	if !strings.Contains(lines[0], "Project: api") || !strings.Contains(lines[2], "> api") {
+		t.Fatalf("expected the steps and the selected project, got:\n%s", view)
+	}
+	if strings.Contains(view, "docs") {
+		t.Fatalf("expected only a page of three rows, got:\n%s", view)
+	}
+
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyTab}, {Type: tea.KeyDown}, {Type: tea.KeyTab}} {
+		next, _ = m.Update(msg)
+		m = next.(model)
+	}
+	if got := strings.Split(m.View(), "\n"); len(got) != 6 || !strings.Contains(got[0], "Session: fix login") {
+		t.Fatalf("expected the picked session in the steps, got:\n%s", m.View())
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.plan == nil || m.plan.ProjectDir != "/src/api" || m.plan.SessionID != "s1" {
+		t.Fatalf("expected the inline picker to launch s1, got %+v", m.plan)
+	}
+}
+
+// sourcedStore reports fixed sources, as a composite store with a broken
+// SQLite database does.
+type sourcedStore struct {
+	opencodestorage.Store
+	sources []opencodestorage.SourceStatus
+}
+
+func (s *sourcedStore) Sources() []opencodestorage.SourceStatus { return s.sources }
+
+func TestStatusBar_ShowsSourcesAndCollapsesWhenNarrow(t *testing.T) {
+	store := &sourcedStore{sources: []opencodestorage.SourceStatus{
+		{Name: opencodestorage.SourceSQLite, Path: "/data/opencode.db", Err: errors.New("unable to open database file")},
+		{Name: opencodestorage.SourceJSON, Path: "/data/storage", Projects: 2, Sessions: 5},
+	}}
+	readAt := time.Date(2026, 3, 1, 9, 30, 15, 0, time.Local)
+	m := newModel(Input{
+		Store:    store,
+		Models:   []config.Model{{Name: "GPT", Model: "openai/gpt-5.2"}},
+		Projects: []opencodestorage.Project{{ID: "p1", Worktree: "/src/api"}, {ID: "p2", Worktree: "/src/web"}},
+		LoadedAt: readAt,
+	})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 30})
+	m = next.(model)
+
+	lines := strings.Split(m.View(), "\n")
+	bar := lines[len(lines)-1]
+	for _, want := range []string{"sqlite /data/opencode.db: unavailable", "json /data/storage: 2 projects, 5 sessions", "read 09:30:15", "⚠ sqlite: unable to open database file"} {
+		if !strings.Contains(bar, want) {
+			t.Fatalf("expected %q in the status bar, got %q", want, bar)
+		}
+	}
+	if len(lines) != 30 {
+		t.Fatalf("expected the status bar to fit the screen, got %d lines", len(lines))
+	}
+
+	if got := m.statusBar(60); ansi.StringWidth(got) > 60 || !strings.Contains(got, "json: 2 projects") || !strings.Contains(got, "⚠ sqlite") || strings.Contains(got, "/data") {
+		t.Fatalf("expected paths and messages to go first in a narrow bar, got %q", got)
+	}
+	if got := m.statusBar(20); ansi.StringWidth(got) > 20 || !strings.Contains(got, "sqlite+json") {
+		t.Fatalf("expected only the source names in a tiny bar, got %q", got)
+	}
+}
+
+func TestLaunchSelection_KeepsAllowedModelAndFallsBackToRuleDefault(t *testing.T) {
+	gpt := config.Model{Name: "GPT", Model: "openai/gpt-5.2"}
+	claude := config.Model{Name: "Claude", Model: "anthropic/claude-sonnet-4"}
+	other := config.Model{Name: "Other", Model: "openai/gpt-4.1"}
+	m := newModel(Input{
+		Models: []config.Model{gpt, claude, other},
+		ProjectModels: func(worktree string) (config.ProjectModels, error) {
+			if worktree == "/src/api" {
+				return config.ProjectModels{Models: []config.Model{gpt, claude}, Default: claude, Matched: true}, nil
+			}
+			return config.ProjectModels{Models: []config.Model{gpt, claude, other}, Default: gpt}, nil
+		},
+	})
+
+	if mdl, _ := m.launchSelection("/src/api", gpt, nil); mdl != gpt {
+		t.Fatalf("expected the allowed pick to survive the rule, got %+v", mdl)
+	}
+	if mdl, _ := m.launchSelection("/src/api", other, nil); mdl != claude {
+		t.Fatalf("expected the rule's default for a model it doesn't allow, got %+v", mdl)
+	}
+}
+
+func TestLaunchSelection_ResolvesProfileAgainstTheSessionsProject(t *testing.T) {
+	gpt := config.Model{Name: "GPT", Model: "openai/gpt-5.2"}
+	claude := config.Model{Name: "Claude", Model: "anthropic/claude-sonnet-4"}
+	review := config.Profile{Name: "review", Model: "Claude", Agent: "plan", Args: []string{"--print-logs"}, Resolved: claude}
+	m := newModel(Input{
+		Models: []config.Model{gpt, claude},
+		ProjectModels: func(worktree string) (config.ProjectModels, error) {
+			switch worktree {
+			case "/src/api":
+				return config.ProjectModels{Models: []config.Model{gpt, claude}, Default: gpt, Profiles: []config.Profile{review}, Matched: true}, nil
+			case "/src/web":
+				return config.ProjectModels{Models: []config.Model{gpt}, Default: gpt, Matched: true}, nil
+			}
+			return config.ProjectModels{Models: []config.Model{claude}, Default: claude}, nil
+		},
+	})
+
+	mdl, p := m.launchSelection("/src/api", claude, &review)
+	if p == nil || p.Agent != "plan" || len(p.Args) != 1 || mdl != claude {
+		t.Fatalf("expected the profile to survive a rule that allows it, got %+v %+v", mdl, p)
+	}
+	if mdl, p := m.launchSelection("/src/web", claude, &review); p != nil || mdl != gpt {
+		t.Fatalf("expected a profile the project doesn't offer to fall back to its default, got %+v %+v", mdl, p)
+	}
+	if mdl, p := m.launchSelection("/src/cli", gpt, nil); p != nil || mdl != claude {
+		t.Fatalf("expected a model from another project to fall back to this one's default, got %+v %+v", mdl, p)
+	}
+	if mdl, _ := m.launchSelection("/src/api", config.Model{}, nil); mdl != gpt {
+		t.Fatalf("expected no preference to launch the project's default, got %+v", mdl)
+	}
+}
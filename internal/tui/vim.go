@@ -0,0 +1,403 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"oc/internal/opencodestorage"
+	"oc/internal/state"
+)
+
+// defaultExportName is the file :export writes in the state directory when no
+// file is given.
+const defaultExportName = "oc-sessions.json"
+
+// vimCommands are the ":" commands, shown in the help overlay.
+var vimCommands = []helpBinding{
+	{key: ":pin", text: "pin or unpin the selection"},
+	{key: ":tag <tags>", text: "add tags to the selected session (no tags: open the editor)"},
+	{key: ":untag <tags>", text: "remove tags from the selected session"},
+	{key: ":note <text>", text: "set the note of the selected session (no text: clear it)"},
+	{key: ":export [file]", text: "write the listed sessions as JSON to file, relative to the state directory (default " + defaultExportName + ")"},
+	{key: ":export! [file]", text: "like :export, but overwrite an existing file"},
+	{key: ":search <query>", text: "open global search with a query"},
+	{key: ":q", text: "quit without launching"},
+}
+
+func newCmdLine() textinput.Model {
+	cmd := textinput.New()
+	cmd.Prompt = ":"
+	cmd.CharLimit = 200
+	return cmd
+}
+
+// updateVim handles a key in vim mode before the usual routing and reports
+// whether it did. In normal mode, motions are replayed as the arrow keys they
+// stand for and other printable keys are dropped instead of filtering; in
+// filter mode keys go to the filter as usual until esc or enter.
+func (m model) updateVim(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	if m.cmdOpen {
+		nm, cmd := m.updateCmdLine(msg)
+		return nm, cmd, true
+	}
+	if m.searchOpen && m.searchNaming {
+		return m, nil, false
+	}
+	if m.vimInsert {
+		if key.Matches(msg, m.keys.Back) || (key.Matches(msg, m.keys.Launch) && !m.searchPicking) {
+			m.vimInsert = false
+			return m, nil, true
+		}
+		return m, nil, false
+	}
+	if msg.Type != tea.KeyRunes {
+		return m, nil, false
+	}
+
+	switch string(msg.Runes) {
+	case "j":
+		return m.vimMove(tea.KeyDown)
+	case "k":
+		return m.vimMove(tea.KeyUp)
+	case "g":
+		return m.vimMove(tea.KeyHome)
+	case "G":
+		return m.vimMove(tea.KeyEnd)
+	case "h", "l":
+		delta := 1
+		if string(msg.Runes) == "h" {
+			delta = -1
+		}
+		switch {
+		case m.searchOpen:
+			if m.searchExpanded {
+				m.stepSearchMatch(delta)
+			}
+		case m.viewMode == viewModeProjects:
+			m.focus = m.nextFocus(delta)
+			m.ensureValidFocus()
+			m.updateFocus()
+		}
+		return m, nil, true
+	case "n", "N":
+		if !m.searchOpen {
+			return m, nil, true
+		}
+		if string(msg.Runes) == "n" {
+			return m.vimMove(tea.KeyDown)
+		}
+		return m.vimMove(tea.KeyUp)
+	case "/":
+		if m.searchOpen || m.viewMode == viewModeRecentSessions || m.focus != focusModels {
+			m.vimInsert = true
+		}
+		return m, nil, true
+	case ":":
+		m.cmdOpen = true
+		m.cmdNote = ""
+		m.cmdline.SetValue("")
+		m.cmdline.Focus()
+		return m, nil, true
+	}
+	if key.Matches(msg, m.keys.Help) {
+		m.help = helpOverlay{open: true}
+		return m, nil, true
+	}
+	if key.Matches(msg, m.keys.all()...) {
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// vimMove moves in the list of the current view as the arrow key t would.
+// Search moves the results directly so up/down don't recall the history.
+func (m model) vimMove(t tea.KeyType) (tea.Model, tea.Cmd, bool) {
+	if m.searchOpen {
+		nm, cmd := m.moveSearchList(tea.KeyMsg{Type: t})
+		return nm, cmd, true
+	}
+	nm, cmd := m.Update(tea.KeyMsg{Type: t})
+	return nm, cmd, true
+}
+
+func (m model) updateCmdLine(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.plan = nil
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back),
+		msg.Type == tea.KeyBackspace && m.cmdline.Value() == "":
+		m.closeCmdLine()
+		return m, nil
+	case key.Matches(msg, m.keys.Launch):
+		line := m.cmdline.Value()
+		m.closeCmdLine()
+		return m.runCommand(line)
+	}
+	var cmd tea.Cmd
+	m.cmdline, cmd = m.cmdline.Update(msg)
+	return m, cmd
+}
+
+func (m *model) closeCmdLine() {
+	m.cmdOpen = false
+	m.cmdline.Blur()
+}
+
+// runCommand runs a ":" command line against the current view.
+func (m model) runCommand(line string) (tea.Model, tea.Cmd) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "":
+		return m, nil
+	case "q", "quit":
+		m.plan = nil
+		return m, tea.Quit
+	case "pin":
+		m.pinSelection()
+	case "tag", "untag", "note":
+		id, title := m.commandSession()
+		switch {
+		case m.notes == nil:
+			m.cmdNote = "tags and notes are unavailable"
+		case id == "":
+			m.cmdNote = ":" + name + " needs a selected session"
+		case name == "tag" && arg == "":
+			m.openNoteEditor(id, title)
+		default:
+			n := m.notes.Get(id)
+			switch name {
+			case "tag":
+				n.Tags = append(n.Tags, state.ParseTags(arg)...)
+			case "untag":
+				n.Tags = withoutTags(n.Tags, state.ParseTags(arg))
+			case "note":
+				n.Text = arg
+			}
+			m.setNote(id, n)
+		}
+	case "export", "export!":
+		m.exportSessions(arg, name == "export!")
+	case "search":
+		m.openSearch()
+		if arg != "" {
+			nm, cmd := m.runSearchQuery(arg)
+			return nm, cmd
+		}
+	default:
+		m.cmdNote = "unknown command: " + name
+	}
+	return m, nil
+}
+
+func withoutTags(tags, drop []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		keep := true
+		for _, d := range drop {
+			if t == d {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// commandSession is the session a command acts on: the selection of the
+// current view, or of the focused sessions column.
+func (m model) commandSession() (id, title string) {
+	switch {
+	case m.searchOpen:
+		if res, ok := m.selectedSearchResult(); ok {
+			return res.Session.ID, res.Session.Title
+		}
+	case m.viewMode == viewModeRecentSessions:
+		if ri, ok := m.recentList.SelectedItem().(recentSessionItem); ok {
+			return ri.res.Session.ID, ri.Title()
+		}
+	case m.focus == focusSessions:
+		if si, ok := m.sesList.SelectedItem().(sessionItem); ok {
+			return si.Session.ID, si.Session.Title
+		}
+	}
+	return "", ""
+}
+
+// pinSelection pins or unpins the selection of the current view.
+func (m *model) pinSelection() {
+	if !m.searchOpen && m.viewMode == viewModeProjects {
+		m.togglePin()
+		return
+	}
+	if id, _ := m.commandSession(); id != "" {
+		m.toggleSessionPin(id)
+	}
+}
+
+// exportedSession is one session written by :export.
+type exportedSession struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Project string    `json:"project"`
+	Updated time.Time `json:"updated"`
+	Tags    []string  `json:"tags,omitempty"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// listedSessions returns the sessions listed in the current view, in order.
+// Collapsed groups are left out.
+func (m model) listedSessions() []exportedSession {
+	out := []exportedSession{}
+	add := func(s opencodestorage.Session, worktree string) {
+		n := m.notes.Get(s.ID)
+		out = append(out, exportedSession{
+			ID:      s.ID,
+			Title:   s.Title,
+			Project: worktree,
+			Updated: time.UnixMilli(s.Updated),
+			Tags:    n.Tags,
+			Note:    n.Text,
+		})
+	}
+	switch {
+	case m.searchOpen:
+		for _, it := range m.searchList.Items() {
+			if si, ok := it.(sessionSearchItem); ok {
+				add(si.res.Session, si.res.ProjectWorktree)
+			}
+		}
+	case m.viewMode == viewModeRecentSessions:
+		for _, it := range m.recentList.Items() {
+			if ri, ok := it.(recentSessionItem); ok {
+				add(ri.res.Session, ri.res.ProjectWorktree)
+			}
+		}
+	default:
+		worktree := ""
+		if p := m.selectedProject(); p != nil {
+			worktree = p.Worktree
+		}
+		for _, it := range m.sesList.Items() {
+			if si, ok := it.(sessionItem); ok {
+				add(si.Session, worktree)
+			}
+		}
+	}
+	return out
+}
+
+// exportSessions writes the listed sessions as JSON to path, resolved by
+// exportPath. An existing file is only replaced when overwrite is set.
+func (m *model) exportSessions(path string, overwrite bool) {
+	path, err := exportPath(path)
+	if err != nil {
+		m.cmdNote = "export failed: " + err.Error()
+		return
+	}
+	sessions := m.listedSessions()
+	b, err := json.MarshalIndent(sessions, "", "  ")
+	if err == nil {
+		err = writeExport(path, append(b, '\n'), overwrite)
+	}
+	if errors.Is(err, os.ErrExist) {
+		m.cmdNote = path + " already exists; use :export! to overwrite it"
+		return
+	}
+	if err != nil {
+		m.cmdNote = "export failed: " + err.Error()
+		return
+	}
+	m.cmdNote = fmt.Sprintf("exported %d sessions to %s", len(sessions), path)
+}
+
+// exportPath resolves the file :export writes: a leading ~/ is the home
+// directory, and a relative path (defaultExportName when arg is empty) is
+// placed in the state directory, which is created if needed.
+func exportPath(arg string) (string, error) {
+	if strings.HasPrefix(arg, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, arg[2:]), nil
+	}
+	if filepath.IsAbs(arg) {
+		return arg, nil
+	}
+	if arg == "" {
+		arg = defaultExportName
+	}
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, arg)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeExport writes b to path, failing with os.ErrExist when path exists
+// and overwrite is not set.
+func writeExport(path string, b []byte, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// vimHint replaces the "type to ..." hint of the help line with the mode.
+func (m model) vimHint() string {
+	switch {
+	case m.vimInsert && m.searchOpen:
+		return "-- SEARCH -- (esc: normal mode)"
+	case m.vimInsert:
+		return "-- FILTER -- (esc: normal mode)"
+	case m.searchOpen:
+		return "-- NORMAL -- (/ search, n/N results, : command)"
+	}
+	return "-- NORMAL -- (/ filter, : command)"
+}
+
+// header is the help line of a view: the bindings plus a hint, replaced by
+// the mode in vim mode and by save or command results. The command line
+// takes its place while open.
+func (m model) header(bindings []helpBinding, hint string) string {
+	if m.cmdOpen {
+		return m.cmdline.View()
+	}
+	tail := hint
+	if m.vim && hint != "" {
+		tail = m.vimHint()
+	}
+	if st := m.saveStatus(); st != "" {
+		tail = st
+	}
+	if m.cmdNote != "" {
+		tail = m.cmdNote
+	}
+	return m.helpLine(bindings, tail)
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strconv"
+	"time"
+
+	"oc/internal/config"
+	"oc/internal/opencodestorage"
+)
+
+// limitSessions applies a session policy to items ordered newest first and
+// returns the visible items plus how many were hidden. Sessions with an
+// unknown message count are never hidden by min_messages.
+func limitSessions[T any](items []T, session func(T) opencodestorage.Session, l config.SessionLimits, now time.Time) ([]T, int) {
+	if l.IsZero() {
+		return items, 0
+	}
+	var cutoff int64
+	if l.MaxAgeDays > 0 {
+		cutoff = now.Add(-time.Duration(l.MaxAgeDays) * 24 * time.Hour).UnixMilli()
+	}
+	out := make([]T, 0, len(items))
+	for _, it := range items {
+		s := session(it)
+		if l.MinMessages > 0 && s.Messages >= 0 && s.Messages < l.MinMessages {
+			continue
+		}
+		if cutoff > 0 && s.Updated < cutoff {
+			continue
+		}
+		if l.MaxCount > 0 && len(out) >= l.MaxCount {
+			break
+		}
+		out = append(out, it)
+	}
+	return out, len(items) - len(out)
+}
+
+// sessionLimitsFor resolves the session policy for p. The legacy
+// ui.global_sessions_max_age_days still applies to General unless the policy
+// sets its own max age.
+func (m model) sessionLimitsFor(p opencodestorage.Project) config.SessionLimits {
+	var l config.SessionLimits
+	if m.sessionLimits != nil {
+		l = m.sessionLimits(p.Worktree)
+	}
+	// The legacy global limit only fills an unset max_age_days; an explicit 0
+	// turns the age limit off.
+	if isGlobalProject(p) && !l.MaxAgeSet && m.globalSessionsMaxAgeDays > 0 {
+		l.MaxAgeDays = m.globalSessionsMaxAgeDays
+	}
+	return l
+}
+
+// hiddenSuffix tells how many sessions the policy hides, or that they are
+// revealed.
+func (m model) hiddenSuffix(hidden int) string {
+	switch {
+	case hidden == 0:
+		return ""
+	case m.revealHidden:
+		return " (showing " + strconv.Itoa(hidden) + " hidden)"
+	}
+	return " (" + strconv.Itoa(hidden) + " hidden)"
+}
+
+func (m model) revealBinding() helpBinding {
+	if m.revealHidden {
+		return bind(m.keys.RevealHidden, "hide")
+	}
+	return bind(m.keys.RevealHidden, "show hidden")
+}